@@ -0,0 +1,171 @@
+package normalized
+
+import (
+	"fmt"
+	"github.com/nar-lang/nar-compiler/ast"
+	"github.com/nar-lang/nar-compiler/ast/typed"
+	"github.com/nar-lang/nar-compiler/common"
+	"maps"
+	"slices"
+)
+
+// FunctionGroup is a group of mutually recursive local functions.
+// Every function of the group is lifted with locals it captures itself and locals captured by
+// functions it references, so it can create their closures.
+type FunctionGroup struct {
+	*expressionBase
+	functions []*Function
+	nested    Expression
+}
+
+func NewFunctionGroup(loc ast.Location, functions []*Function, nested Expression) Expression {
+	return &FunctionGroup{
+		expressionBase: newExpressionBase(loc),
+		functions:      functions,
+		nested:         nested,
+	}
+}
+
+func (e *FunctionGroup) flattenLambdas(parentName ast.Identifier, m *Module, locals map[ast.Identifier]Pattern) Expression {
+	groupNames := map[ast.Identifier]Pattern{}
+	for _, fn := range e.functions {
+		groupNames[fn.name] = NewPNamed(fn.location, nil, fn.name)
+	}
+
+	outerLocals := maps.Clone(locals)
+	for name := range groupNames {
+		delete(outerLocals, name)
+	}
+
+	captures := e.captures(outerLocals, groupNames)
+
+	lambdaDefs := make([]Definition, len(e.functions))
+	replacements := make([]Expression, len(e.functions))
+	for i, fn := range e.functions {
+		lambdaDefs[i], replacements[i] = m.liftLambda(
+			fn.location, parentName, fn.params, fn.body, outerLocals, captures[i], fn.name, fn.location)
+		fn.predecessor.SetSuccessor(lambdaDefs[i].body())
+	}
+
+	for i, fn := range e.functions {
+		lambdaDef := lambdaDefs[i]
+		replaceMap := map[ast.Identifier]Expression{}
+		referenced := map[ast.Identifier]struct{}{}
+		lambdaDef.body().extractUsedLocalsSet(groupNames, referenced)
+
+		for j, other := range e.functions {
+			if _, ok := referenced[other.name]; !ok {
+				continue
+			}
+			if len(captures[j]) == 0 {
+				replaceMap[other.name] = NewGlobal(fn.location, m.name, lambdaDefs[j].name())
+				continue
+			}
+			//captures of referenced function are subset of own captures, pass them to its closure
+			var closureArgs []Expression
+			for _, arg := range captures[j] {
+				k := slices.Index(captures[i], arg)
+				closureArgs = append(closureArgs, NewLocal(fn.location, arg, lambdaDef.params()[k], fn.predecessor))
+			}
+			selfName := ast.Identifier(fmt.Sprintf("_self_%s", other.name))
+			selfPattern := NewPNamed(fn.location, nil, selfName)
+			lambdaDef.setBody(NewLet(fn.location,
+				selfPattern,
+				NewApply(fn.location, NewGlobal(fn.location, m.name, lambdaDefs[j].name()), closureArgs),
+				lambdaDef.body()))
+			replaceMap[other.name] = NewLocal(fn.location, selfName, selfPattern, fn.predecessor)
+		}
+		lambdaDef.setBody(lambdaDef.body().replaceLocals(replaceMap))
+		paramNames := extractParamNames(lambdaDef.params())
+		lambdaDef.setBody(lambdaDef.body().flattenLambdas(lambdaDef.name(), m, paramNames))
+	}
+
+	replaceMap := map[ast.Identifier]Expression{}
+	referenced := map[ast.Identifier]struct{}{}
+	e.nested.extractUsedLocalsSet(groupNames, referenced)
+
+	var closures []*Let
+	for i, fn := range e.functions {
+		if _, ok := referenced[fn.name]; !ok {
+			continue
+		}
+		if len(captures[i]) == 0 {
+			replaceMap[fn.name] = replacements[i]
+			continue
+		}
+		replName := ast.Identifier(fmt.Sprintf("_lmbd_closure_%s", fn.name))
+		patternName := NewPNamed(fn.location, nil, replName)
+		replaceMap[fn.name] = NewLocal(fn.location, replName, patternName, fn.predecessor)
+		closures = append(closures, NewLet(fn.location, patternName, replacements[i], nil).(*Let))
+	}
+
+	result := e.nested.replaceLocals(replaceMap)
+	for i := len(closures) - 1; i >= 0; i-- {
+		closures[i].nested = result
+		result = closures[i]
+	}
+	return result.flattenLambdas(parentName, m, locals)
+}
+
+// captures returns sorted names of outer locals every function of the group captures.
+// Function captures locals it uses and locals captured by functions of the group it references,
+// so it is able to create their closures
+func (e *FunctionGroup) captures(
+	outerLocals map[ast.Identifier]Pattern, groupNames map[ast.Identifier]Pattern,
+) [][]ast.Identifier {
+	captured := make([]map[ast.Identifier]struct{}, len(e.functions))
+	references := make([][]int, len(e.functions))
+	for i, fn := range e.functions {
+		captured[i] = map[ast.Identifier]struct{}{}
+		for _, name := range extractUsedLocals(fn.body, outerLocals, extractParamNames(fn.params)) {
+			captured[i][name] = struct{}{}
+		}
+		referenced := map[ast.Identifier]struct{}{}
+		fn.body.extractUsedLocalsSet(groupNames, referenced)
+		for j, other := range e.functions {
+			if _, ok := referenced[other.name]; ok {
+				references[i] = append(references[i], j)
+			}
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for i := range e.functions {
+			for _, j := range references[i] {
+				for name := range captured[j] {
+					if _, ok := captured[i][name]; !ok {
+						captured[i][name] = struct{}{}
+						changed = true
+					}
+				}
+			}
+		}
+	}
+
+	result := make([][]ast.Identifier, len(e.functions))
+	for i := range e.functions {
+		result[i] = common.Keys(captured[i])
+		slices.Sort(result[i])
+	}
+	return result
+}
+
+func (e *FunctionGroup) replaceLocals(replace map[ast.Identifier]Expression) Expression {
+	for _, fn := range e.functions {
+		fn.body = fn.body.replaceLocals(replace)
+	}
+	e.nested = e.nested.replaceLocals(replace)
+	return e
+}
+
+func (e *FunctionGroup) extractUsedLocalsSet(definedLocals map[ast.Identifier]Pattern, usedLocals map[ast.Identifier]struct{}) {
+	for _, fn := range e.functions {
+		fn.body.extractUsedLocalsSet(definedLocals, usedLocals)
+	}
+	e.nested.extractUsedLocalsSet(definedLocals, usedLocals)
+}
+
+func (*FunctionGroup) annotate(ctx *typed.SolvingContext, typeParams typeParamsMap, modules map[ast.QualifiedIdentifier]*Module, typedModules map[ast.QualifiedIdentifier]*typed.Module, moduleName ast.QualifiedIdentifier, stack []*typed.Definition) (typed.Expression, error) {
+	return nil, common.NewCompilerError("FunctionGroup should be removed with flattenLambdas() before annotation")
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/nar-lang/nar-compiler/ast"
 	"github.com/nar-lang/nar-compiler/ast/typed"
 	"github.com/nar-lang/nar-compiler/common"
+	"slices"
+	"strings"
 )
 
 type Module struct {
@@ -43,10 +45,18 @@ func (module *Module) extractLambda(
 	loc ast.Location, parentName ast.Identifier, params []Pattern, body Expression,
 	locals map[ast.Identifier]Pattern, name ast.Identifier, nameLocation ast.Location,
 ) (def Definition, usedLocals []ast.Identifier, replacement Expression) {
-	lastLambdaId++
-	lambdaName := ast.Identifier(fmt.Sprintf("_lmbd_%s_%d_%s", parentName, lastLambdaId, name))
 	paramNames := extractParamNames(params)
 	usedLocals = extractUsedLocals(body, locals, paramNames)
+	def, replacement = module.liftLambda(loc, parentName, params, body, locals, usedLocals, name, nameLocation)
+	return
+}
+
+func (module *Module) liftLambda(
+	loc ast.Location, parentName ast.Identifier, params []Pattern, body Expression,
+	locals map[ast.Identifier]Pattern, usedLocals []ast.Identifier, name ast.Identifier, nameLocation ast.Location,
+) (def Definition, replacement Expression) {
+	lastLambdaId++
+	lambdaName := ast.Identifier(fmt.Sprintf("_lmbd_%s_%d_%s", parentName, lastLambdaId, name))
 	LastDefinitionId++
 	localParams := common.Map(func(x ast.Identifier) Pattern { return NewPNamed(loc, nil, x) }, usedLocals)
 	params = append(localParams, params...)
@@ -71,10 +81,29 @@ func (module *Module) Annotate(
 	modules map[ast.QualifiedIdentifier]*Module,
 	typedModules map[ast.QualifiedIdentifier]*typed.Module,
 ) (errors []error) {
+	return module.annotate(modules, typedModules, nil)
+}
+
+// annotate annotates dependencies of the module before the module itself,
+// stack contains names of modules being annotated and is used to report import cycles
+func (module *Module) annotate(
+	modules map[ast.QualifiedIdentifier]*Module,
+	typedModules map[ast.QualifiedIdentifier]*typed.Module,
+	stack []ast.QualifiedIdentifier,
+) (errors []error) {
+	if i := slices.Index(stack, module.name); i >= 0 {
+		cycle := make([]string, 0, len(stack)-i+1)
+		for _, name := range stack[i:] {
+			cycle = append(cycle, string(name))
+		}
+		cycle = append(cycle, string(module.name))
+		return []error{common.NewErrorOf(module, "import cycle: %s", strings.Join(cycle, " -> "))}
+	}
 	if _, ok := typedModules[module.name]; ok {
 		return
 	}
 
+	stack = append(stack, module.name)
 	for depName := range module.dependencies {
 		if depName == module.name {
 			continue
@@ -84,7 +113,7 @@ func (module *Module) Annotate(
 			errors = append(errors, common.NewErrorOf(module, "module dependency `%s` not found", depName))
 			return
 		}
-		if err := depModule.Annotate(modules, typedModules); err != nil {
+		if err := depModule.annotate(modules, typedModules, stack); err != nil {
 			errors = append(errors, err...)
 			return
 		}
@@ -95,6 +124,10 @@ func (module *Module) Annotate(
 
 	for i := 0; i < len(module.definitions); i++ {
 		def := module.definitions[i]
+		if _, ok := o.FindDefinition(def.name()); ok {
+			//already annotated as a dependency of recursive definition
+			continue
+		}
 		typedDef, err := def.annotate(modules, typedModules, module.name, nil)
 		if err != nil {
 			errors = append(errors, err)
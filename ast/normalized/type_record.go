@@ -9,12 +9,14 @@ import (
 type TRecord struct {
 	*typeBase
 	fields map[ast.Identifier]Type
+	row    Type
 }
 
-func NewTRecord(loc ast.Location, fields map[ast.Identifier]Type) Type {
+func NewTRecord(loc ast.Location, fields map[ast.Identifier]Type, row Type) Type {
 	return &TRecord{
 		typeBase: newTypeBase(loc),
 		fields:   fields,
+		row:      row,
 	}
 }
 
@@ -30,5 +32,21 @@ func (e *TRecord) annotate(ctx *typed.SolvingContext, params typeParamsMap, sour
 			return nil, err
 		}
 	}
-	return e.setSuccessor(typed.NewTRecord(e.location, fields, false))
+	var row typed.Type
+	if e.row != nil {
+		var err error
+		row, err = e.row.annotate(ctx, params, source, placeholders)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e.setSuccessor(typed.NewTRecord(e.location, fields, row))
+}
+
+func (e *TRecord) Fields() map[ast.Identifier]Type {
+	return e.fields
+}
+
+func (e *TRecord) Row() Type {
+	return e.row
 }
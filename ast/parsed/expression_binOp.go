@@ -24,6 +24,8 @@ func (e *BinOp) SemanticTokens() []ast.SemanticToken {
 	for _, i := range e.items {
 		if i.operand != nil {
 			tokens = append(tokens, i.operand.SemanticTokens()...)
+		} else if i.name != "" {
+			tokens = append(tokens, i.location.ToToken(ast.TokenTypeFunction))
 		}
 	}
 	return tokens
@@ -62,7 +64,10 @@ func (e *BinOp) normalize(
 		if o1.operand != nil {
 			output = append(output, o1)
 		} else {
-			if infixFn, _, ids := module.findInfixFn(modules, o1.infix); len(ids) != 1 {
+			if o1.name != "" {
+				o1.fn = NewInfix(o1.location, false, ast.InfixIdentifier(o1.name), Left,
+					NamedInfixPrecedence, o1.location, ast.Identifier(o1.name))
+			} else if infixFn, _, ids := module.findInfixFn(modules, o1.infix); len(ids) != 1 {
 				return nil, newAmbiguousInfixError(ids, o1.infix, e.location)
 			} else {
 				o1.fn = infixFn
@@ -86,48 +91,44 @@ func (e *BinOp) normalize(
 
 	var buildTree func() (normalized.Expression, error)
 	buildTree = func() (normalized.Expression, error) {
-		op := output[len(output)-1].infix
+		op := output[len(output)-1]
 		output = output[:len(output)-1]
 
-		if infixA, m, ids := module.findInfixFn(modules, op); len(ids) != 1 {
-			return nil, newAmbiguousInfixError(ids, op, e.location)
+		fn, err := op.normalizeFunc(e.location, locals, modules, module, normalizedModule)
+		if err != nil {
+			return nil, err
+		}
+
+		var left, right normalized.Expression
+		r := output[len(output)-1]
+		if r.operand != nil {
+			right, err = r.operand.normalize(locals, modules, module, normalizedModule)
+			if err != nil {
+				return nil, err
+			}
+			output = output[:len(output)-1]
 		} else {
-			var left, right normalized.Expression
-			var err error
-			r := output[len(output)-1]
-			if r.operand != nil {
-				right, err = r.operand.normalize(locals, modules, module, normalizedModule)
-				if err != nil {
-					return nil, err
-				}
-				output = output[:len(output)-1]
-			} else {
-				right, err = buildTree()
-				if err != nil {
-					return nil, err
-				}
+			right, err = buildTree()
+			if err != nil {
+				return nil, err
 			}
+		}
 
-			l := output[len(output)-1]
-			if l.operand != nil {
-				left, err = l.operand.normalize(locals, modules, module, normalizedModule)
-				if err != nil {
-					return nil, err
-				}
-				output = output[:len(output)-1]
-			} else {
-				left, err = buildTree()
-				if err != nil {
-					return nil, err
-				}
+		l := output[len(output)-1]
+		if l.operand != nil {
+			left, err = l.operand.normalize(locals, modules, module, normalizedModule)
+			if err != nil {
+				return nil, err
+			}
+			output = output[:len(output)-1]
+		} else {
+			left, err = buildTree()
+			if err != nil {
+				return nil, err
 			}
-
-			return normalized.NewApply(
-				e.location,
-				normalized.NewGlobal(e.location, m.name, infixA.alias()),
-				[]normalized.Expression{left, right},
-			), nil
 		}
+
+		return normalized.NewApply(e.location, fn, []normalized.Expression{left, right}), nil
 	}
 
 	tree, err := buildTree()
@@ -137,10 +138,15 @@ func (e *BinOp) normalize(
 	return e.setSuccessor(tree)
 }
 
+// NamedInfixPrecedence is a precedence of named function applied in infix form, e.g. `a |max| b`
+const NamedInfixPrecedence = 9
+
 type BinOpItem struct {
-	operand Expression
-	infix   ast.InfixIdentifier
-	fn      Infix
+	operand  Expression
+	infix    ast.InfixIdentifier
+	name     ast.QualifiedIdentifier
+	location ast.Location
+	fn       Infix
 }
 
 func NewBinOpOperand(expression Expression) *BinOpItem {
@@ -154,3 +160,31 @@ func NewBinOpFunc(infix ast.InfixIdentifier) *BinOpItem {
 		infix: infix,
 	}
 }
+
+func NewBinOpNamedFunc(location ast.Location, name ast.QualifiedIdentifier) *BinOpItem {
+	return &BinOpItem{
+		name:     name,
+		location: location,
+	}
+}
+
+func (i *BinOpItem) Infix() ast.InfixIdentifier {
+	return i.infix
+}
+
+func (i *BinOpItem) normalizeFunc(
+	loc ast.Location,
+	locals map[ast.Identifier]normalized.Pattern,
+	modules map[ast.QualifiedIdentifier]*Module,
+	module *Module,
+	normalizedModule *normalized.Module,
+) (normalized.Expression, error) {
+	if i.name != "" {
+		return NewVar(i.location, i.name).normalize(locals, modules, module, normalizedModule)
+	}
+	if infixA, m, ids := module.findInfixFn(modules, i.infix); len(ids) != 1 {
+		return nil, newAmbiguousInfixError(ids, i.infix, loc)
+	} else {
+		return normalized.NewGlobal(loc, m.name, infixA.alias()), nil
+	}
+}
@@ -65,28 +65,41 @@ func (e *Function) normalize(
 ) (normalized.Expression, error) {
 	innerLocals := maps.Clone(locals)
 	innerLocals[e.name] = normalized.NewPNamed(e.nameLocation, nil, e.name)
+	params, body, declaredType, err := e.normalizeFunction(innerLocals, modules, module, normalizedModule)
+	if err != nil {
+		return nil, err
+	}
+	nested, err := e.nested.normalize(innerLocals, modules, module, normalizedModule)
+	if err != nil {
+		return nil, err
+	}
+	return e.setSuccessor(normalized.NewFunction(e.location, e.name, params, body, declaredType, nested, e))
+}
+
+func (e *Function) normalizeFunction(
+	locals map[ast.Identifier]normalized.Pattern,
+	modules map[ast.QualifiedIdentifier]*Module,
+	module *Module,
+	normalizedModule *normalized.Module,
+) ([]normalized.Pattern, normalized.Expression, normalized.Type, error) {
 	var params []normalized.Pattern
 	for _, param := range e.params {
-		nParam, err := param.normalize(innerLocals, modules, module, normalizedModule)
+		nParam, err := param.normalize(locals, modules, module, normalizedModule)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 		params = append(params, nParam)
 	}
-	body, err := e.body.normalize(innerLocals, modules, module, normalizedModule)
-	if err != nil {
-		return nil, err
-	}
-	nested, err := e.nested.normalize(innerLocals, modules, module, normalizedModule)
+	body, err := e.body.normalize(locals, modules, module, normalizedModule)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	var declaredType normalized.Type
 	if e.declaredType != nil {
 		declaredType, err = e.declaredType.normalize(modules, module, nil)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 	}
-	return e.setSuccessor(normalized.NewFunction(e.location, e.name, params, body, declaredType, nested, e))
+	return params, body, declaredType, nil
 }
@@ -0,0 +1,90 @@
+package parsed
+
+import (
+	"github.com/nar-lang/nar-compiler/ast"
+	"github.com/nar-lang/nar-compiler/ast/normalized"
+	"maps"
+	"slices"
+)
+
+// JoinFunctionGroup joins local function with the functions declared right after it
+// into a group of mutually recursive functions. If fn is not a function or it is not
+// followed by another function with a different name fn is returned as is.
+func JoinFunctionGroup(location ast.Location, fn Expression) Expression {
+	head, ok := fn.(*Function)
+	if !ok {
+		return fn
+	}
+	var functions []*Function
+	var nested Expression
+	switch next := head.nested.(type) {
+	case *Function:
+		functions = []*Function{next}
+		nested = next.nested
+	case *FunctionGroup:
+		functions = next.functions
+		nested = next.nested
+	default:
+		return fn
+	}
+	if slices.ContainsFunc(functions, func(x *Function) bool { return x.name == head.name }) {
+		return fn
+	}
+	for _, f := range functions {
+		f.nested = nil
+	}
+	head.nested = nil
+	return &FunctionGroup{
+		expressionBase: newExpressionBase(location),
+		functions:      append([]*Function{head}, functions...),
+		nested:         nested,
+	}
+}
+
+type FunctionGroup struct {
+	*expressionBase
+	functions []*Function
+	nested    Expression
+}
+
+func (e *FunctionGroup) SemanticTokens() []ast.SemanticToken {
+	return nil
+}
+
+func (e *FunctionGroup) Iterate(f func(statement Statement)) {
+	f(e)
+	for _, fn := range e.functions {
+		fn.Iterate(f)
+	}
+	if e.nested != nil {
+		e.nested.Iterate(f)
+	}
+}
+
+func (e *FunctionGroup) normalize(
+	locals map[ast.Identifier]normalized.Pattern,
+	modules map[ast.QualifiedIdentifier]*Module,
+	module *Module,
+	normalizedModule *normalized.Module,
+) (normalized.Expression, error) {
+	innerLocals := maps.Clone(locals)
+	for _, fn := range e.functions {
+		innerLocals[fn.name] = normalized.NewPNamed(fn.nameLocation, nil, fn.name)
+	}
+	var functions []*normalized.Function
+	for _, fn := range e.functions {
+		params, body, declaredType, err := fn.normalizeFunction(
+			maps.Clone(innerLocals), modules, module, normalizedModule)
+		if err != nil {
+			return nil, err
+		}
+		nFn := normalized.NewFunction(fn.location, fn.name, params, body, declaredType, nil, fn)
+		fn.setSuccessor(nFn)
+		functions = append(functions, nFn.(*normalized.Function))
+	}
+	nested, err := e.nested.normalize(innerLocals, modules, module, normalizedModule)
+	if err != nil {
+		return nil, err
+	}
+	return e.setSuccessor(normalized.NewFunctionGroup(e.location, functions, nested))
+}
@@ -0,0 +1,104 @@
+package parsed
+
+import (
+	"github.com/nar-lang/nar-compiler/ast"
+	"github.com/nar-lang/nar-compiler/ast/normalized"
+	"github.com/nar-lang/nar-compiler/common"
+)
+
+// NewInterpolation creates interpolated string expression. Parts are string constants and
+// expressions of `${}` holes in order of appearance. Holes are converted to String with
+// `Nar.Base.String.show` if it is defined, otherwise every hole should be of String type.
+func NewInterpolation(location ast.Location, parts []Expression) Expression {
+	return &Interpolation{
+		expressionBase: newExpressionBase(location),
+		parts:          parts,
+	}
+}
+
+type Interpolation struct {
+	*expressionBase
+	parts []Expression
+}
+
+func (e *Interpolation) SemanticTokens() []ast.SemanticToken {
+	return nil
+}
+
+func (e *Interpolation) Iterate(f func(statement Statement)) {
+	f(e)
+	for _, part := range e.parts {
+		if part != nil {
+			part.Iterate(f)
+		}
+	}
+}
+
+func (e *Interpolation) normalize(
+	locals map[ast.Identifier]normalized.Pattern,
+	modules map[ast.QualifiedIdentifier]*Module,
+	module *Module,
+	normalizedModule *normalized.Module,
+) (normalized.Expression, error) {
+	stringModule, ok := modules[common.NarBaseStringName]
+	if !ok {
+		return nil, common.NewErrorOf(e,
+			"string interpolation requires module `%s`", common.NarBaseStringName)
+	}
+	appendDef, _, ids := stringModule.FindDefinition(nil, ast.QualifiedIdentifier(common.NarAppendName))
+	if len(ids) != 1 {
+		return nil, common.NewErrorOf(e,
+			"string interpolation requires definition `%s`",
+			common.MakeFullIdentifier(common.NarBaseStringName, common.NarAppendName))
+	}
+	normalizedModule.AddDependencies(stringModule.name, appendDef.Name())
+
+	//holes are converted to strings with `show` if base library has it, otherwise they should be strings
+	showDef, _, ids := stringModule.FindDefinition(nil, ast.QualifiedIdentifier(common.NarShowName))
+	if len(ids) == 1 {
+		normalizedModule.AddDependencies(stringModule.name, showDef.Name())
+	} else {
+		showDef = nil
+	}
+
+	var parts []normalized.Expression
+	if len(e.parts) == 0 || !isStringConst(e.parts[0]) {
+		parts = append(parts, normalized.NewConst(e.location, ast.CString{}))
+	}
+	for _, part := range e.parts {
+		nPart, err := part.normalize(locals, modules, module, normalizedModule)
+		if err != nil {
+			return nil, err
+		}
+		if showDef != nil && !isStringConst(part) {
+			nPart = normalized.NewApply(
+				part.Location(),
+				normalized.NewGlobal(part.Location(), stringModule.name, showDef.Name()),
+				[]normalized.Expression{nPart},
+			)
+		}
+		parts = append(parts, nPart)
+	}
+
+	if len(parts) == 1 {
+		return e.setSuccessor(parts[0])
+	}
+
+	result := parts[0]
+	for _, part := range parts[1:] {
+		result = normalized.NewApply(
+			e.location,
+			normalized.NewGlobal(part.Location(), stringModule.name, appendDef.Name()),
+			[]normalized.Expression{result, part},
+		)
+	}
+	return e.setSuccessor(result)
+}
+
+func isStringConst(e Expression) bool {
+	if c, ok := e.(*Const); ok {
+		_, ok = c.value.(ast.CString)
+		return ok
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package parsed
+
+import (
+	"github.com/nar-lang/nar-compiler/ast"
+	"github.com/nar-lang/nar-compiler/ast/normalized"
+)
+
+const sectionParamName = ast.Identifier("_section")
+
+// NewSection creates operator section, i.e. infix function with only one operand given.
+// Left section `(1 +)` has its operand on the left side, right section `(+ 1)` on the right one.
+func NewSection(location ast.Location, fn *BinOpItem, operand Expression, left bool) Expression {
+	return &Section{
+		expressionBase: newExpressionBase(location),
+		fn:             fn,
+		operand:        operand,
+		left:           left,
+		inParentheses:  !left,
+	}
+}
+
+type Section struct {
+	*expressionBase
+	fn            *BinOpItem
+	operand       Expression
+	left          bool
+	inParentheses bool
+}
+
+func (e *Section) SemanticTokens() []ast.SemanticToken {
+	return nil
+}
+
+func (e *Section) Iterate(f func(statement Statement)) {
+	f(e)
+	if e.operand != nil {
+		e.operand.Iterate(f)
+	}
+}
+
+func (e *Section) SetInParentheses(inParentheses bool) {
+	e.inParentheses = inParentheses
+}
+
+func (e *Section) InParentheses() bool {
+	return e.inParentheses
+}
+
+func (e *Section) Left() bool {
+	return e.left
+}
+
+func (e *Section) Func() *BinOpItem {
+	return e.fn
+}
+
+func (e *Section) Operand() Expression {
+	return e.operand
+}
+
+func (e *Section) normalize(
+	locals map[ast.Identifier]normalized.Pattern,
+	modules map[ast.QualifiedIdentifier]*Module,
+	module *Module,
+	normalizedModule *normalized.Module,
+) (normalized.Expression, error) {
+	param := NewVar(e.location, ast.QualifiedIdentifier(sectionParamName))
+	var items []*BinOpItem
+	if e.left {
+		items = []*BinOpItem{NewBinOpOperand(e.operand), e.fn, NewBinOpOperand(param)}
+	} else {
+		items = []*BinOpItem{NewBinOpOperand(param), e.fn, NewBinOpOperand(e.operand)}
+	}
+	lambda := NewLambda(
+		e.location,
+		[]Pattern{NewPNamed(e.location, sectionParamName, e.location)},
+		nil,
+		NewBinOp(e.location, items, true))
+	nLambda, err := lambda.normalize(locals, modules, module, normalizedModule)
+	if err != nil {
+		return nil, err
+	}
+	return e.setSuccessor(nLambda)
+}
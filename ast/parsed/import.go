@@ -14,6 +14,9 @@ type Import interface {
 	Module() ast.QualifiedIdentifier
 	unwrap(modules map[ast.QualifiedIdentifier]*Module) error
 	Alias() *ast.Identifier
+	Location() ast.Location
+	Used() bool
+	markUsed()
 }
 
 func NewImport(
@@ -34,6 +37,19 @@ type import_ struct {
 	alias       *ast.Identifier
 	exposingAll bool
 	exposing    []string
+	used        bool
+}
+
+func (i *import_) Location() ast.Location {
+	return i.location
+}
+
+func (i *import_) Used() bool {
+	return i.used
+}
+
+func (i *import_) markUsed() {
+	i.used = true
 }
 
 func (i *import_) Alias() *ast.Identifier {
@@ -4,6 +4,7 @@ import (
 	"github.com/nar-lang/nar-compiler/ast"
 	"github.com/nar-lang/nar-compiler/ast/normalized"
 	"github.com/nar-lang/nar-compiler/common"
+	"slices"
 	"strings"
 	"unicode"
 )
@@ -16,6 +17,7 @@ type Module struct {
 	infixFns    []Infix
 	definitions []Definition
 	dataTypes   []DataType
+	generated   bool
 
 	packageName        ast.PackageIdentifier
 	referencedPackages map[ast.PackageIdentifier]struct{}
@@ -57,11 +59,15 @@ func (module *Module) SetReferencedPackages(referencedPackages map[ast.PackageId
 	module.referencedPackages = referencedPackages
 }
 
+// Generate flattens data types once and unwraps imports, it is called again for cached modules on recompilation
 func (module *Module) Generate(modules map[ast.QualifiedIdentifier]*Module) (errors []error) {
-	for _, dt := range module.dataTypes {
-		alias, defs := dt.flatten(module.name)
-		module.aliases = append(module.aliases, alias)
-		module.definitions = append(module.definitions, defs...)
+	if !module.generated {
+		for _, dt := range module.dataTypes {
+			alias, defs := dt.flatten(module.name)
+			module.aliases = append(module.aliases, alias)
+			module.definitions = append(module.definitions, defs...)
+		}
+		module.generated = true
 	}
 
 	return module.unwrapImports(modules)
@@ -71,6 +77,24 @@ func (module *Module) Normalize(
 	modules map[ast.QualifiedIdentifier]*Module,
 	normalizedModules map[ast.QualifiedIdentifier]*normalized.Module,
 ) (errors []error) {
+	return module.normalize(modules, normalizedModules, nil)
+}
+
+// normalize normalizes module and its dependencies, stack contains names of modules being normalized
+// and is used to report import cycles
+func (module *Module) normalize(
+	modules map[ast.QualifiedIdentifier]*Module,
+	normalizedModules map[ast.QualifiedIdentifier]*normalized.Module,
+	stack []ast.QualifiedIdentifier,
+) (errors []error) {
+	if i := slices.Index(stack, module.name); i >= 0 {
+		cycle := make([]string, 0, len(stack)-i+1)
+		for _, name := range stack[i:] {
+			cycle = append(cycle, string(name))
+		}
+		cycle = append(cycle, string(module.name))
+		return []error{common.NewErrorOf(module, "import cycle: %s", strings.Join(cycle, " -> "))}
+	}
 	if _, ok := normalizedModules[module.name]; ok {
 		return
 	}
@@ -89,14 +113,18 @@ func (module *Module) Normalize(
 
 	normalizedModules[module.name] = o
 
+	stack = append(stack, module.name)
 	for _, modName := range o.Dependencies() {
+		if modName == module.name {
+			continue
+		}
 		depModule, ok := modules[modName]
 		if !ok {
-			errors = append(errors, common.NewErrorOf(depModule, "module `%s` not found", modName))
+			errors = append(errors, common.NewErrorOf(module, "module `%s` not found", modName))
 			continue
 		}
 
-		if err := depModule.Normalize(modules, normalizedModules); err != nil {
+		if err := depModule.normalize(modules, normalizedModules, stack); err != nil {
 			errors = append(errors, err...)
 		}
 	}
@@ -147,6 +175,7 @@ func (module *Module) findInfixFn(
 	if modules != nil {
 		for _, imp := range module.imports {
 			if imp.exposes(string(name)) {
+				imp.markUsed()
 				return modules[imp.Module()].findInfixFn(nil, name)
 			}
 		}
@@ -202,6 +231,7 @@ func (module *Module) findType(
 		for _, imp := range module.imports {
 
 			if imp.exposes(string(name)) {
+				imp.markUsed()
 				return modules[imp.Module()].findType(nil, typeName, args, loc)
 			}
 		}
@@ -318,6 +348,7 @@ func (module *Module) FindDefinition(
 	if modules != nil {
 		for _, imp := range module.imports {
 			if imp.exposes(string(name)) {
+				imp.markUsed()
 				return modules[imp.Module()].FindDefinition(nil, defName)
 			}
 		}
@@ -410,3 +441,56 @@ func (module *Module) Definitions() []Definition {
 func (module *Module) Imports() []Import {
 	return module.imports
 }
+
+// Lint returns warnings about imports that were not used to resolve any name of the module
+// and local functions that cannot be called. Local functions are lifted to globals during normalization,
+// so they are checked here.
+func (module *Module) Lint() (warnings []error) {
+	for _, imp := range module.imports {
+		if !imp.Used() {
+			warnings = append(warnings, common.NewErrorAt(imp.Location(), "import `%s` is not used", imp.Module()))
+		}
+	}
+	module.Iterate(func(statement Statement) {
+		var functions []*Function
+		var nested Expression
+		switch x := statement.(type) {
+		case *Function:
+			functions, nested = []*Function{x}, x.nested
+		case *FunctionGroup:
+			functions, nested = x.functions, x.nested
+		}
+		if nested == nil {
+			return
+		}
+		//function is used if it is reachable from nested expression
+		used := map[*Function]struct{}{}
+		queue := []Expression{nested}
+		for len(queue) > 0 {
+			e := queue[0]
+			queue = queue[1:]
+			for _, fn := range functions {
+				if _, ok := used[fn]; !ok && usesName(e, fn.name) {
+					used[fn] = struct{}{}
+					queue = append(queue, fn.body)
+				}
+			}
+		}
+		for _, fn := range functions {
+			if _, ok := used[fn]; !ok && !strings.HasPrefix(string(fn.name), "_") {
+				warnings = append(warnings, common.NewErrorAt(fn.nameLocation, "local function `%s` is not used", fn.name))
+			}
+		}
+	})
+	return
+}
+
+func usesName(e Expression, name ast.Identifier) bool {
+	found := false
+	e.Iterate(func(statement Statement) {
+		if v, ok := statement.(*Var); ok && v.name == ast.QualifiedIdentifier(name) {
+			found = true
+		}
+	})
+	return found
+}
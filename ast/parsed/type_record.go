@@ -3,18 +3,22 @@ package parsed
 import (
 	"github.com/nar-lang/nar-compiler/ast"
 	"github.com/nar-lang/nar-compiler/ast/normalized"
+	"github.com/nar-lang/nar-compiler/common"
+	"maps"
 )
 
-func NewTRecord(loc ast.Location, fields map[ast.Identifier]Type) Type {
+func NewTRecord(loc ast.Location, fields map[ast.Identifier]Type, row Type) Type {
 	return &TRecord{
 		typeBase: newTypeBase(loc),
 		fields:   fields,
+		row:      row,
 	}
 }
 
 type TRecord struct {
 	*typeBase
 	fields map[ast.Identifier]Type
+	row    Type
 }
 
 func (t *TRecord) SemanticTokens() []ast.SemanticToken {
@@ -23,6 +27,9 @@ func (t *TRecord) SemanticTokens() []ast.SemanticToken {
 
 func (t *TRecord) Iterate(f func(statement Statement)) {
 	f(t)
+	if t.row != nil {
+		t.row.Iterate(f)
+	}
 	for _, field := range t.fields {
 		if field != nil {
 			field.Iterate(f)
@@ -34,6 +41,10 @@ func (t *TRecord) Fields() map[ast.Identifier]Type {
 	return t.fields
 }
 
+func (t *TRecord) Row() Type {
+	return t.row
+}
+
 func (t *TRecord) normalize(modules map[ast.QualifiedIdentifier]*Module, module *Module, namedTypes namedTypeMap) (normalized.Type, error) {
 	fields := map[ast.Identifier]normalized.Type{}
 	for n, v := range t.fields {
@@ -43,7 +54,31 @@ func (t *TRecord) normalize(modules map[ast.QualifiedIdentifier]*Module, module
 			return nil, err
 		}
 	}
-	return t.setSuccessor(normalized.NewTRecord(t.location, fields))
+	if t.row == nil {
+		return t.setSuccessor(normalized.NewTRecord(t.location, fields, nil))
+	}
+
+	row, err := t.row.normalize(modules, module, namedTypes)
+	if err != nil {
+		return nil, err
+	}
+	switch row.(type) {
+	case *normalized.TParameter:
+		return t.setSuccessor(normalized.NewTRecord(t.location, fields, row))
+	case *normalized.TRecord:
+		//row is instantiated with another record, so its fields extend ours
+		ext := row.(*normalized.TRecord)
+		merged := maps.Clone(ext.Fields())
+		for name, f := range fields {
+			if _, ok := merged[name]; ok {
+				return nil, common.NewErrorOf(t, "record field `%s` is declared twice", name)
+			}
+			merged[name] = f
+		}
+		return t.setSuccessor(normalized.NewTRecord(t.location, merged, ext.Row()))
+	default:
+		return nil, common.NewErrorOf(t, "record can only be extended with another record")
+	}
 }
 
 func (t *TRecord) applyArgs(params map[ast.Identifier]Type, loc ast.Location) (Type, error) {
@@ -55,5 +90,12 @@ func (t *TRecord) applyArgs(params map[ast.Identifier]Type, loc ast.Location) (T
 			return nil, err
 		}
 	}
-	return NewTRecord(loc, fields), nil
+	var row Type
+	if t.row != nil {
+		row, err = t.row.applyArgs(params, loc)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return NewTRecord(loc, fields, row), nil
 }
@@ -0,0 +1,410 @@
+package typed
+
+import (
+	"fmt"
+	"github.com/nar-lang/nar-compiler/ast"
+	"github.com/nar-lang/nar-compiler/bytecode"
+	"github.com/nar-lang/nar-compiler/common"
+	"slices"
+	"strings"
+)
+
+// occurrence is a path to the object inside of select condition, e.g. [1, 0] is the first argument
+// of the second argument of the condition. Objects at non-empty paths are stored in temporary locals.
+type occurrence []int
+
+func (o occurrence) local() string {
+	sb := strings.Builder{}
+	sb.WriteString("_dt")
+	for _, i := range o {
+		sb.WriteString(fmt.Sprintf("_%d", i))
+	}
+	return sb.String()
+}
+
+// decisionTree is a node of the tree compiled from the matrix of simplified select patterns.
+// Leaf nodes refer to the matched case, other nodes test data option of the object at the occurrence
+// so every object is inspected at most once.
+type decisionTree struct {
+	caseIndex  int
+	occurrence occurrence
+	union      *TData
+	branches   []*decisionBranch
+	fallback   *decisionTree
+}
+
+type decisionBranch struct {
+	option *DataOption
+	next   *decisionTree
+}
+
+type decisionRow struct {
+	patterns  []simplePattern
+	caseIndex int
+}
+
+// buildDecisionTree compiles select cases to decision tree. It returns false if some pattern
+// cannot be tested with a switch (literals and lists) or tree does not test anything
+func buildDecisionTree(cases []*SelectCase) (*decisionTree, bool) {
+	var rows []decisionRow
+	for i, cs := range cases {
+		if !isSwitchable(cs.pattern) {
+			return nil, false
+		}
+		rows = append(rows, decisionRow{patterns: []simplePattern{cs.pattern.simplify()}, caseIndex: i})
+	}
+	tree, err := compileDecisionTree(rows, []occurrence{{}})
+	if err != nil || !tree.hasSwitch() {
+		return nil, false
+	}
+	return tree, true
+}
+
+// isSwitchable tells if pattern is made of data options and tuples only
+func isSwitchable(p Pattern) bool {
+	switch x := p.(type) {
+	case *PAny, *PNamed, *PRecord:
+		return true
+	case *PAlias:
+		return isSwitchable(x.nested)
+	case *PConst:
+		_, ok := x.value.(ast.CUnit)
+		return ok
+	case *POption:
+		for _, arg := range x.args {
+			if !isSwitchable(arg) {
+				return false
+			}
+		}
+		return true
+	case *PTuple:
+		for _, item := range x.items {
+			if !isSwitchable(item) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func compileDecisionTree(rows []decisionRow, occurrences []occurrence) (*decisionTree, error) {
+	if len(rows) == 0 {
+		return &decisionTree{caseIndex: -1}, nil
+	}
+	column := slices.IndexFunc(rows[0].patterns, func(p simplePattern) bool {
+		_, ok := p.(simpleConstructor)
+		return ok
+	})
+	if column < 0 {
+		return &decisionTree{caseIndex: rows[0].caseIndex}, nil
+	}
+
+	//move tested column to the front, so rows can be specialized
+	occurrences = append([]occurrence{occurrences[column]},
+		append(slices.Clone(occurrences[:column]), occurrences[column+1:]...)...)
+	matrix := make([][]simplePattern, len(rows))
+	for i, row := range rows {
+		matrix[i] = append([]simplePattern{row.patterns[column]},
+			append(slices.Clone(row.patterns[:column]), row.patterns[column+1:]...)...)
+	}
+
+	tree := &decisionTree{
+		caseIndex:  -1,
+		occurrence: occurrences[0],
+		union:      rows[0].patterns[column].(simpleConstructor).Union,
+	}
+	options, complete := isComplete(matrix)
+	if !complete {
+		ctors := collectCtors(matrix)
+		options = common.Filter(func(o *DataOption) bool {
+			_, ok := ctors[o.name]
+			return ok
+		}, tree.union.options)
+	}
+
+	for _, option := range options {
+		subOccurrences := make([]occurrence, len(option.values))
+		for i := range option.values {
+			subOccurrences[i] = append(slices.Clone(occurrences[0]), i)
+		}
+		subOccurrences = append(subOccurrences, occurrences[1:]...)
+
+		var subRows []decisionRow
+		for i, row := range matrix {
+			patterns, ok, err := specializeRowByCtor(option)(row)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				subRows = append(subRows, decisionRow{patterns: patterns, caseIndex: rows[i].caseIndex})
+			}
+		}
+		next, err := compileDecisionTree(subRows, subOccurrences)
+		if err != nil {
+			return nil, err
+		}
+		tree.branches = append(tree.branches, &decisionBranch{option: option, next: next})
+	}
+
+	if !complete {
+		var subRows []decisionRow
+		for i, row := range matrix {
+			patterns, ok, err := specializeRowByAnything(row)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				subRows = append(subRows, decisionRow{patterns: patterns, caseIndex: rows[i].caseIndex})
+			}
+		}
+		var err error
+		tree.fallback, err = compileDecisionTree(subRows, occurrences[1:])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tree, nil
+}
+
+// isTuple tells if union is a synthetic type of tuple or unit pattern that has the only option
+// and does not exist at runtime
+func (t *decisionTree) isTuple() bool {
+	return strings.HasPrefix(string(t.union.name), "!!")
+}
+
+func (t *decisionTree) needsSwitch() bool {
+	return len(t.branches) > 1 || t.fallback != nil
+}
+
+func (t *decisionTree) hasSwitch() bool {
+	if t.needsSwitch() {
+		return true
+	}
+	for _, b := range t.branches {
+		if b.next.hasSwitch() {
+			return true
+		}
+	}
+	return false
+}
+
+// decisionTreeEmitter appends bytecode of the decision tree.
+// Condition object is expected to be on the top of the stack.
+type decisionTreeEmitter struct {
+	loc       bytecode.Location
+	cases     []*SelectCase
+	self      *Definition
+	binary    *bytecode.Binary
+	hash      *bytecode.BinaryHash
+	ops       []bytecode.Op
+	locations []bytecode.Location
+	// used contains occurrences that are tested or bound by some case
+	used map[string]struct{}
+	// caseStarts contains indices of the first op of every emitted case
+	caseStarts map[int]int
+	jumpToEnd  []int
+}
+
+func appendDecisionTreeBytecode(
+	loc bytecode.Location, tree *decisionTree, cases []*SelectCase, self *Definition,
+	ops []bytecode.Op, locations []bytecode.Location, binary *bytecode.Binary, hash *bytecode.BinaryHash,
+) ([]bytecode.Op, []bytecode.Location, []int) {
+	e := &decisionTreeEmitter{
+		loc:        loc,
+		cases:      cases,
+		self:       self,
+		binary:     binary,
+		hash:       hash,
+		ops:        ops,
+		locations:  locations,
+		used:       map[string]struct{}{},
+		caseStarts: map[int]int{},
+	}
+	for _, cs := range cases {
+		collectUsedOccurrences(cs.pattern, occurrence{}, e.used)
+	}
+	e.appendTree(tree)
+	return e.ops, e.locations, e.jumpToEnd
+}
+
+// collectUsedOccurrences marks occurrences of pattern that contain something to test or bind
+func collectUsedOccurrences(p Pattern, o occurrence, used map[string]struct{}) {
+	switch x := p.(type) {
+	case *PAny:
+		return
+	case *PConst:
+		return
+	case *PAlias:
+		collectUsedOccurrences(x.nested, o, used)
+	case *POption:
+		for i, arg := range x.args {
+			collectUsedOccurrences(arg, append(slices.Clone(o), i), used)
+		}
+	case *PTuple:
+		for i, item := range x.items {
+			collectUsedOccurrences(item, append(slices.Clone(o), i), used)
+		}
+	}
+	used[o.local()] = struct{}{}
+}
+
+func (e *decisionTreeEmitter) appendTree(t *decisionTree) {
+	if t.branches == nil && t.fallback == nil {
+		if t.caseIndex < 0 {
+			//unreachable for exhaustive patterns
+			e.appendJumpToEnd()
+			return
+		}
+		e.appendCase(t.caseIndex)
+		return
+	}
+	if !t.needsSwitch() {
+		if option := t.branches[0].option; e.usesArguments(t, option) {
+			e.loadOccurrence(t.occurrence)
+			e.appendDestructure(t, option)
+			e.popOccurrence(t.occurrence)
+		}
+		e.appendTree(t.branches[0].next)
+		return
+	}
+
+	e.loadOccurrence(t.occurrence)
+	options := make([]string, len(t.branches))
+	for i, b := range t.branches {
+		options[i] = string(b.option.name)
+	}
+	switchIndex := len(e.ops)
+	e.ops, e.locations = bytecode.AppendSwitch(options, e.loc, e.ops, e.locations, e.binary, e.hash)
+	_, _, _, tableIndex := e.ops[switchIndex].Decompose()
+
+	for i, b := range t.branches {
+		//nested switches may reallocate jump tables, so they are accessed by index
+		e.binary.JumpTables[tableIndex].Cases[i].Delta = int32(len(e.ops) - switchIndex - 1)
+		e.appendDestructure(t, b.option)
+		e.popOccurrence(t.occurrence)
+		e.appendTree(b.next)
+	}
+	if t.fallback != nil {
+		e.binary.JumpTables[tableIndex].Default = int32(len(e.ops) - switchIndex - 1)
+		e.popOccurrence(t.occurrence)
+		e.appendTree(t.fallback)
+	}
+}
+
+// loadOccurrence pushes object at occurrence to the stack. Condition object is already there
+func (e *decisionTreeEmitter) loadOccurrence(o occurrence) {
+	if len(o) > 0 {
+		e.ops, e.locations = bytecode.AppendLoadLocal(o.local(), e.loc, e.ops, e.locations, e.binary, e.hash)
+	}
+}
+
+// popOccurrence removes object loaded by loadOccurrence from the stack
+func (e *decisionTreeEmitter) popOccurrence(o occurrence) {
+	if len(o) > 0 {
+		e.ops, e.locations = bytecode.AppendSwapPop(e.loc, bytecode.SwapPopModePop, e.ops, e.locations)
+	}
+}
+
+// usesArguments tells if some argument of the object at the tree occurrence is tested or bound by cases
+func (e *decisionTreeEmitter) usesArguments(t *decisionTree, option *DataOption) bool {
+	for i := range option.values {
+		if _, ok := e.used[append(slices.Clone(t.occurrence), i).local()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// appendDestructure stores used arguments of the object on the top of the stack to temporary locals.
+// Object is known to be of given option, so the match always succeeds
+func (e *decisionTreeEmitter) appendDestructure(t *decisionTree, option *DataOption) {
+	if !e.usesArguments(t, option) {
+		return
+	}
+	for i := range option.values {
+		sub := append(slices.Clone(t.occurrence), i)
+		if _, ok := e.used[sub.local()]; ok {
+			e.ops, e.locations = bytecode.AppendMakePattern(
+				bytecode.PatternKindNamed, sub.local(), 0, e.loc, e.ops, e.locations, e.binary, e.hash)
+		} else {
+			e.ops, e.locations = bytecode.AppendMakePattern(
+				bytecode.PatternKindAny, "", 0, e.loc, e.ops, e.locations, e.binary, e.hash)
+		}
+	}
+	if t.isTuple() {
+		e.ops, e.locations = bytecode.AppendMakePattern(
+			bytecode.PatternKindTuple, "", uint8(len(option.values)), e.loc, e.ops, e.locations, e.binary, e.hash)
+	} else {
+		e.ops, e.locations = bytecode.AppendMakePattern(
+			bytecode.PatternKindDataOption, string(option.name), uint8(len(option.values)),
+			e.loc, e.ops, e.locations, e.binary, e.hash)
+	}
+	e.ops, e.locations = bytecode.AppendJump(0, true, e.loc, e.ops, e.locations)
+}
+
+// appendCase binds locals of the matched case and appends its expression.
+// Case that is reached from several leaves is emitted once.
+func (e *decisionTreeEmitter) appendCase(caseIndex int) {
+	if start, ok := e.caseStarts[caseIndex]; ok {
+		e.ops, e.locations = bytecode.AppendJump(start-len(e.ops)-1, false, e.loc, e.ops, e.locations)
+		return
+	}
+	e.caseStarts[caseIndex] = len(e.ops)
+	cs := e.cases[caseIndex]
+	e.appendBindings(cs.pattern, occurrence{})
+	e.ops, e.locations = appendTailBytecode(cs.expression, e.self, e.ops, e.locations, e.binary, e.hash)
+	e.jumpToEnd = append(e.jumpToEnd, len(e.ops))
+	e.ops, e.locations = bytecode.AppendJump(0, false, cs.location.Bytecode(), e.ops, e.locations)
+}
+
+func (e *decisionTreeEmitter) appendBindings(p Pattern, o occurrence) {
+	switch x := p.(type) {
+	case *PNamed, *PRecord:
+		e.loadOccurrence(o)
+		e.ops, e.locations = x.appendBytecode(e.ops, e.locations, e.binary, e.hash)
+		e.ops, e.locations = bytecode.AppendJump(0, true, e.loc, e.ops, e.locations)
+		e.popOccurrence(o)
+	case *PAlias:
+		e.loadOccurrence(o)
+		e.ops, e.locations = bytecode.AppendMakePattern(
+			bytecode.PatternKindNamed, string(x.alias), 0, x.location.Bytecode(), e.ops, e.locations, e.binary, e.hash)
+		e.ops, e.locations = bytecode.AppendJump(0, true, e.loc, e.ops, e.locations)
+		e.popOccurrence(o)
+		e.appendBindings(x.nested, o)
+	case *POption:
+		for i, arg := range x.args {
+			e.appendBindings(arg, append(slices.Clone(o), i))
+		}
+	case *PTuple:
+		for i, item := range x.items {
+			e.appendBindings(item, append(slices.Clone(o), i))
+		}
+	}
+}
+
+func (e *decisionTreeEmitter) appendJumpToEnd() {
+	e.jumpToEnd = append(e.jumpToEnd, len(e.ops))
+	e.ops, e.locations = bytecode.AppendJump(0, false, e.loc, e.ops, e.locations)
+}
+
+// appendCasesBytecode appends cases that are matched one by one in source order.
+// If none of cases is matched execution continues after the last one.
+// Returns indices of jumps to the end of select.
+func appendCasesBytecode(
+	cases []*SelectCase, self *Definition,
+	ops []bytecode.Op, locations []bytecode.Location, binary *bytecode.Binary, hash *bytecode.BinaryHash,
+) ([]bytecode.Op, []bytecode.Location, []int) {
+	var jumpToEndIndices []int
+	for _, cs := range cases {
+		ops, locations = cs.pattern.appendBytecode(ops, locations, binary, hash)
+		matchOpIndex := len(ops)
+		ops, locations = bytecode.AppendJump(0, true, cs.location.Bytecode(), ops, locations)
+		ops, locations = appendTailBytecode(cs.expression, self, ops, locations, binary, hash)
+		jumpToEndIndices = append(jumpToEndIndices, len(ops))
+		ops, locations = bytecode.AppendJump(0, false, cs.location.Bytecode(), ops, locations)
+		//jump to the next case
+		ops[matchOpIndex] = ops[matchOpIndex].WithDelta(int32(len(ops) - matchOpIndex - 1))
+	}
+	return ops, locations, jumpToEndIndices
+}
@@ -180,7 +180,7 @@ func (def *Definition) Bytecode(pathId ast.FullIdentifier, modName ast.Qualified
 			ops, locations = bytecode.AppendJump(0, true, p.Location().Bytecode(), ops, locations)
 			ops, locations = bytecode.AppendSwapPop(p.Location().Bytecode(), bytecode.SwapPopModePop, ops, locations)
 		}
-		ops, locations = def.body.appendBytecode(ops, locations, binary, hash)
+		ops, locations = appendTailBytecode(def.body, def, ops, locations, binary, hash)
 	}
 
 	return bytecode.Func{
@@ -240,6 +240,12 @@ func (def *Definition) checkPatterns() error {
 	return nil
 }
 
+func (def *Definition) optimize(o *optimizer) {
+	if def.body != nil {
+		def.body = def.body.optimize(o)
+	}
+}
+
 func (def *Definition) SetDeclaredType(declaredType Type) {
 	def.declaredType = declaredType
 }
@@ -255,3 +261,21 @@ func (def *Definition) Name() ast.Identifier {
 func (def *Definition) NameLocation() ast.Location {
 	return def.nameLocation
 }
+
+// appendTailBytecode appends expression in tail position of the self definition body,
+// applies in tail positions are compiled to tail calls
+func appendTailBytecode(
+	e Expression, self *Definition,
+	ops []bytecode.Op, locations []bytecode.Location, binary *bytecode.Binary, hash *bytecode.BinaryHash,
+) ([]bytecode.Op, []bytecode.Location) {
+	switch e := e.(type) {
+	case *Apply:
+		return e.appendTailBytecode(self, ops, locations, binary, hash)
+	case *Let:
+		return e.appendTailBytecode(self, ops, locations, binary, hash)
+	case *Select:
+		return e.appendTailBytecode(self, ops, locations, binary, hash)
+	default:
+		return e.appendBytecode(ops, locations, binary, hash)
+	}
+}
@@ -12,6 +12,7 @@ type Expression interface {
 	appendEquations(eqs Equations, loc *ast.Location, localDefs localTypesMap, ctx *SolvingContext, stack []*Definition) (Equations, error)
 	mapTypes(subst map[uint64]Type) error
 	checkPatterns() error
+	optimize(o *optimizer) Expression
 	setAnnotation(annotatedType *TUnbound)
 }
 
@@ -24,6 +24,11 @@ func (e *Access) checkPatterns() error {
 	return e.record.checkPatterns()
 }
 
+func (e *Access) optimize(o *optimizer) Expression {
+	e.record = e.record.optimize(o)
+	return e
+}
+
 func (e *Access) mapTypes(subst map[uint64]Type) error {
 	var err error
 	e.type_, err = e.type_.mapTo(subst)
@@ -45,7 +50,7 @@ func (e *Access) appendEquations(eqs Equations, loc *ast.Location, localDefs loc
 	var err error
 	fields := map[ast.Identifier]Type{}
 	fields[e.fieldName] = e.type_
-	eqs = append(eqs, NewEquation(e, NewTRecord(e.location, fields, true), e.record.Type()))
+	eqs = append(eqs, NewEquation(e, NewTRecord(e.location, fields, ctx.newTypeAnnotation(e)), e.record.Type()))
 	eqs, err = e.record.appendEquations(eqs, loc, localDefs, ctx, stack)
 	if err != nil {
 		return nil, err
@@ -37,6 +37,22 @@ func (e *Apply) checkPatterns() error {
 	return nil
 }
 
+func (e *Apply) optimize(o *optimizer) Expression {
+	e.func_ = e.func_.optimize(o)
+	for i, arg := range e.args {
+		e.args[i] = arg.optimize(o)
+	}
+	if fn, ok := e.func_.(*Global); ok {
+		if folded, ok := foldMath(e.expressionBase, common.MakeFullIdentifier(fn.moduleName, fn.definitionName), e.args); ok {
+			return folded
+		}
+	}
+	if inlined, ok := o.inlineApply(e); ok {
+		return inlined
+	}
+	return e
+}
+
 func (e *Apply) mapTypes(subst map[uint64]Type) error {
 	var err error
 	e.type_, err = e.type_.mapTo(subst)
@@ -55,7 +71,7 @@ func (e *Apply) mapTypes(subst map[uint64]Type) error {
 func (e *Apply) Children() []Statement {
 	children := e.expressionBase.Children()
 	children = append(children, e.func_)
-	return append(common.Map(func(x Expression) Statement { return x }, e.args))
+	return append(children, common.Map(func(x Expression) Statement { return x }, e.args)...)
 }
 
 func (e *Apply) Code(currentModule ast.QualifiedIdentifier) string {
@@ -86,18 +102,33 @@ func (e *Apply) appendEquations(eqs Equations, loc *ast.Location, localDefs loca
 }
 
 func (e *Apply) appendBytecode(ops []bytecode.Op, locations []bytecode.Location, binary *bytecode.Binary, hash *bytecode.BinaryHash) ([]bytecode.Op, []bytecode.Location) {
-	var err error
+	return e.appendTailBytecode(nil, ops, locations, binary, hash)
+}
+
+// appendTailBytecode appends apply in tail position of the self definition body.
+// If self is nil apply is not in tail position.
+func (e *Apply) appendTailBytecode(self *Definition, ops []bytecode.Op, locations []bytecode.Location, binary *bytecode.Binary, hash *bytecode.BinaryHash) ([]bytecode.Op, []bytecode.Location) {
 	for _, arg := range e.args {
 		ops, locations = arg.appendBytecode(ops, locations, binary, hash)
-		if err != nil {
-			return nil, nil
+	}
+	if g, ok := e.func_.(*Global); ok && g.definition != nil && len(e.args) < len(g.definition.params) {
+		//partial application of known function is a closure
+		//unknown function is reported by Global.appendBytecode
+		id := common.MakeFullIdentifier(g.moduleName, g.definitionName)
+		if funcIndex, ok := hash.FuncsMap[bytecode.FullIdentifier(id)]; ok {
+			return bytecode.AppendMakeClosure(funcIndex, uint8(len(e.args)), e.location.Bytecode(), ops, locations)
 		}
 	}
-	ops, locations = e.func_.appendBytecode(ops, locations, binary, hash)
-	if err != nil {
-		return nil, nil
+	if self == nil {
+		ops, locations = e.func_.appendBytecode(ops, locations, binary, hash)
+		return bytecode.AppendApply(uint8(len(e.args)), e.location.Bytecode(), ops, locations)
+	}
+	if g, ok := e.func_.(*Global); ok && g.definition != nil && g.definition.id == self.id && len(e.args) == len(self.params) {
+		//jump to the beginning of the function
+		return bytecode.AppendTailJump(uint8(len(e.args)), -len(ops)-1, e.location.Bytecode(), ops, locations)
 	}
-	return bytecode.AppendApply(uint8(len(e.args)), e.location.Bytecode(), ops, locations)
+	ops, locations = e.func_.appendBytecode(ops, locations, binary, hash)
+	return bytecode.AppendTailApply(uint8(len(e.args)), e.location.Bytecode(), ops, locations)
 }
 
 func (e *Apply) Func() Expression {
@@ -33,6 +33,16 @@ func (e *Call) checkPatterns() error {
 	return nil
 }
 
+func (e *Call) optimize(o *optimizer) Expression {
+	for i, arg := range e.args {
+		e.args[i] = arg.optimize(o)
+	}
+	if folded, ok := foldMath(e.expressionBase, e.name, e.args); ok {
+		return folded
+	}
+	return e
+}
+
 func (e *Call) mapTypes(subst map[uint64]Type) error {
 	var err error
 	e.type_, err = e.type_.mapTo(subst)
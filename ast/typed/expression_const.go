@@ -25,6 +25,10 @@ func (e *Const) checkPatterns() error {
 	return nil
 }
 
+func (e *Const) optimize(o *optimizer) Expression {
+	return e
+}
+
 func (e *Const) mapTypes(subst map[uint64]Type) error {
 	var err error
 	e.type_, err = e.type_.mapTo(subst)
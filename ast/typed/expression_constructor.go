@@ -38,6 +38,13 @@ func (e *Constructor) checkPatterns() error {
 	return nil
 }
 
+func (e *Constructor) optimize(o *optimizer) Expression {
+	for i, arg := range e.args {
+		e.args[i] = arg.optimize(o)
+	}
+	return e
+}
+
 func (e *Constructor) mapTypes(subst map[uint64]Type) error {
 	var err error
 	e.type_, err = e.type_.mapTo(subst)
@@ -31,6 +31,13 @@ func (e *Global) checkPatterns() error {
 	return nil
 }
 
+func (e *Global) optimize(o *optimizer) Expression {
+	if inlined, ok := o.inlineGlobal(e); ok {
+		return inlined
+	}
+	return e
+}
+
 func (e *Global) mapTypes(subst map[uint64]Type) error {
 	var err error
 	e.type_, err = e.type_.mapTo(subst)
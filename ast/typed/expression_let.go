@@ -32,6 +32,15 @@ func (e *Let) checkPatterns() error {
 	return e.body.checkPatterns()
 }
 
+func (e *Let) optimize(o *optimizer) Expression {
+	e.value = e.value.optimize(o)
+	e.body = e.body.optimize(o)
+	if named, ok := e.pattern.(*PNamed); ok && !usesLocal(e.body, named.name) && isPure(e.value) {
+		return e.body
+	}
+	return e
+}
+
 func (e *Let) mapTypes(subst map[uint64]Type) error {
 	var err error
 	e.type_, err = e.type_.mapTo(subst)
@@ -83,9 +92,13 @@ func (e *Let) appendEquations(eqs Equations, loc *ast.Location, localDefs localT
 }
 
 func (e *Let) appendBytecode(ops []bytecode.Op, locations []bytecode.Location, binary *bytecode.Binary, hash *bytecode.BinaryHash) ([]bytecode.Op, []bytecode.Location) {
+	return e.appendTailBytecode(nil, ops, locations, binary, hash)
+}
+
+func (e *Let) appendTailBytecode(self *Definition, ops []bytecode.Op, locations []bytecode.Location, binary *bytecode.Binary, hash *bytecode.BinaryHash) ([]bytecode.Op, []bytecode.Location) {
 	ops, locations = e.value.appendBytecode(ops, locations, binary, hash)
 	ops, locations = e.pattern.appendBytecode(ops, locations, binary, hash)
 	ops, locations = bytecode.AppendJump(0, true, e.location.Bytecode(), ops, locations)
 	ops, locations = bytecode.AppendSwapPop(e.location.Bytecode(), bytecode.SwapPopModePop, ops, locations)
-	return e.body.appendBytecode(ops, locations, binary, hash)
+	return appendTailBytecode(e.body, self, ops, locations, binary, hash)
 }
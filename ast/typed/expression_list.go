@@ -7,8 +7,6 @@ import (
 	"github.com/nar-lang/nar-compiler/common"
 )
 
-// TODO: BUG -- list executed in reverse order
-
 type List struct {
 	*expressionBase
 	items    []Expression
@@ -33,6 +31,13 @@ func (e *List) checkPatterns() error {
 	return nil
 }
 
+func (e *List) optimize(o *optimizer) Expression {
+	for i, item := range e.items {
+		e.items[i] = item.optimize(o)
+	}
+	return e
+}
+
 func (e *List) mapTypes(subst map[uint64]Type) error {
 	var err error
 	e.type_, err = e.type_.mapTo(subst)
@@ -24,6 +24,10 @@ func (e *Local) checkPatterns() error {
 	return nil
 }
 
+func (e *Local) optimize(o *optimizer) Expression {
+	return e
+}
+
 func (e *Local) mapTypes(subst map[uint64]Type) error {
 	var err error
 	e.type_, err = e.type_.mapTo(subst)
@@ -28,6 +28,13 @@ func (e *Record) checkPatterns() error {
 	return nil
 }
 
+func (e *Record) optimize(o *optimizer) Expression {
+	for _, f := range e.fields {
+		f.value = f.value.optimize(o)
+	}
+	return e
+}
+
 func (e *Record) mapTypes(subst map[uint64]Type) error {
 	var err error
 	e.type_, err = e.type_.mapTo(subst)
@@ -68,7 +75,7 @@ func (e *Record) appendEquations(eqs Equations, loc *ast.Location, localDefs loc
 		fieldTypes[f.name] = f.type_
 	}
 
-	typeRecord := NewTRecord(e.location, fieldTypes, false)
+	typeRecord := NewTRecord(e.location, fieldTypes, nil)
 	eqs = append(eqs, NewEquation(e, e.type_, typeRecord))
 
 	for _, f := range e.fields {
@@ -38,6 +38,33 @@ func (e *Select) checkPatterns() error {
 	return nil
 }
 
+func (e *Select) optimize(o *optimizer) Expression {
+	e.condition = e.condition.optimize(o)
+	for _, cs := range e.cases {
+		cs.expression = cs.expression.optimize(o)
+	}
+
+	var cases []*SelectCase
+	for _, cs := range e.cases {
+		match := staticMatch(cs.pattern, e.condition)
+		if match == staticMatchNever {
+			continue
+		}
+		if match == staticMatchAlways {
+			if len(cases) == 0 && isBindingFree(cs.pattern) && isPure(e.condition) {
+				return cs.expression
+			}
+			cases = append(cases, cs)
+			break
+		}
+		cases = append(cases, cs)
+	}
+	if len(cases) > 0 {
+		e.cases = cases
+	}
+	return e
+}
+
 func (e *Select) mapTypes(subst map[uint64]Type) error {
 	var err error
 	e.type_, err = e.type_.mapTo(subst)
@@ -108,21 +135,17 @@ func (e *Select) appendEquations(eqs Equations, loc *ast.Location, localDefs loc
 }
 
 func (e *Select) appendBytecode(ops []bytecode.Op, locations []bytecode.Location, binary *bytecode.Binary, hash *bytecode.BinaryHash) ([]bytecode.Op, []bytecode.Location) {
+	return e.appendTailBytecode(nil, ops, locations, binary, hash)
+}
+
+func (e *Select) appendTailBytecode(self *Definition, ops []bytecode.Op, locations []bytecode.Location, binary *bytecode.Binary, hash *bytecode.BinaryHash) ([]bytecode.Op, []bytecode.Location) {
 	ops, locations = e.condition.appendBytecode(ops, locations, binary, hash)
 	var jumpToEndIndices []int
-	var prevMatchOpIndex int
-	for i, cs := range e.cases {
-		if i > 0 {
-			//jump to the next case
-			ops[prevMatchOpIndex] = ops[prevMatchOpIndex].WithDelta(int32(len(ops) - prevMatchOpIndex - 1))
-		}
-
-		ops, locations = cs.pattern.appendBytecode(ops, locations, binary, hash)
-		prevMatchOpIndex = len(ops)
-		ops, locations = bytecode.AppendJump(0, true, cs.location.Bytecode(), ops, locations)
-		ops, locations = cs.expression.appendBytecode(ops, locations, binary, hash)
-		jumpToEndIndices = append(jumpToEndIndices, len(ops))
-		ops, locations = bytecode.AppendJump(0, false, cs.location.Bytecode(), ops, locations)
+	if tree, ok := buildDecisionTree(e.cases); ok {
+		ops, locations, jumpToEndIndices = appendDecisionTreeBytecode(
+			e.location.Bytecode(), tree, e.cases, self, ops, locations, binary, hash)
+	} else {
+		ops, locations, jumpToEndIndices = appendCasesBytecode(e.cases, self, ops, locations, binary, hash)
 	}
 
 	selectEndIndex := len(ops)
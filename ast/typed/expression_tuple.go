@@ -28,6 +28,13 @@ func (e Tuple) checkPatterns() error {
 	return nil
 }
 
+func (e *Tuple) optimize(o *optimizer) Expression {
+	for i, item := range e.items {
+		e.items[i] = item.optimize(o)
+	}
+	return e
+}
+
 func (e Tuple) mapTypes(subst map[uint64]Type) error {
 	var err error
 	e.type_, err = e.type_.mapTo(subst)
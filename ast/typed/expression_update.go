@@ -39,6 +39,13 @@ func (e *Update) checkPatterns() error {
 	return nil
 }
 
+func (e *Update) optimize(o *optimizer) Expression {
+	for _, f := range e.fields {
+		f.value = f.value.optimize(o)
+	}
+	return e
+}
+
 func NewUpdateLocal(
 	ctx *SolvingContext, loc ast.Location,
 	recordName ast.Identifier, target Pattern, fields []*RecordField,
@@ -96,7 +103,7 @@ func (e *Update) appendEquations(eqs Equations, loc *ast.Location, localDefs loc
 		fieldTypes[f.name] = f.type_
 	}
 
-	eqs = append(eqs, NewEquation(e, e.type_, NewTRecord(e.location, fieldTypes, true)))
+	eqs = append(eqs, NewEquation(e, e.type_, NewTRecord(e.location, fieldTypes, ctx.newTypeAnnotation(e))))
 
 	for _, f := range e.fields {
 		l := loc
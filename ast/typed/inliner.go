@@ -0,0 +1,352 @@
+package typed
+
+import (
+	"fmt"
+	"github.com/nar-lang/nar-compiler/ast"
+	"strings"
+)
+
+// inlineMaxSize is the maximal number of expression nodes in the body of inlined definition
+const inlineMaxSize = 12
+
+// inlineLocalPrefix starts names of locals that bind arguments of inlined applications.
+// Names include module name, so they are unique in any function the body is inlined to
+const inlineLocalPrefix = "_inl_"
+
+type optimizer struct {
+	moduleName ast.QualifiedIdentifier
+	recursive  map[*Definition]bool
+	lastLocal  uint64
+}
+
+func newOptimizer(moduleName ast.QualifiedIdentifier) *optimizer {
+	return &optimizer{
+		moduleName: moduleName,
+		recursive:  map[*Definition]bool{},
+	}
+}
+
+// inlineApply replaces saturated application of small global definition with its body
+func (o *optimizer) inlineApply(e *Apply) (Expression, bool) {
+	fn, ok := e.func_.(*Global)
+	if !ok || !o.canInline(fn) || len(fn.definition.params) != len(e.args) || len(e.args) == 0 {
+		return nil, false
+	}
+
+	replace := map[ast.Identifier]Expression{}
+	var bindings []*Let
+	for i, param := range fn.definition.params {
+		named, ok := param.(*PNamed)
+		if !ok {
+			return nil, false
+		}
+		arg := e.args[i]
+		switch arg.(type) {
+		case *Const, *Local, *Global:
+			replace[named.name] = arg
+		default:
+			//impure arguments are bound in call order even if unused
+			if isPure(arg) && countLocalUses(fn.definition.body, named.name) <= 1 {
+				replace[named.name] = arg
+				continue
+			}
+			o.lastLocal++
+			pattern := &PNamed{
+				patternBase: &patternBase{location: arg.Location(), type_: arg.Type()},
+				name:        ast.Identifier(fmt.Sprintf("%s%s_%d", inlineLocalPrefix, o.moduleName, o.lastLocal)),
+			}
+			replace[named.name] = &Local{
+				expressionBase: &expressionBase{location: arg.Location(), type_: arg.Type()},
+				name:           pattern.name,
+				target:         pattern,
+			}
+			bindings = append(bindings, &Let{
+				expressionBase: &expressionBase{location: e.location, type_: e.type_},
+				pattern:        pattern,
+				value:          arg,
+			})
+		}
+	}
+
+	result, ok := substituteLocals(fn.definition.body, replace)
+	if !ok {
+		return nil, false
+	}
+	result = result.optimize(o)
+	for i := len(bindings) - 1; i >= 0; i-- {
+		bindings[i].body = result
+		result = bindings[i]
+	}
+	return result, true
+}
+
+// inlineGlobal replaces reference to definition without parameters with its body
+// if the body is a constant or a constructor without arguments
+func (o *optimizer) inlineGlobal(e *Global) (Expression, bool) {
+	if !o.canInline(e) || len(e.definition.params) > 0 {
+		return nil, false
+	}
+	switch body := e.definition.body.(type) {
+	case *Const:
+		return substituteLocals(body, nil)
+	case *Constructor:
+		if len(body.args) == 0 {
+			return substituteLocals(body, nil)
+		}
+	}
+	return nil, false
+}
+
+func (o *optimizer) canInline(e *Global) bool {
+	def := e.definition
+	if def == nil || def.body == nil {
+		return false
+	}
+	if e.moduleName != o.moduleName && (def.hidden || referencesHidden(def.body, e.moduleName)) {
+		return false
+	}
+	if !isInlinable(def.body) || expressionSize(def.body) > inlineMaxSize {
+		return false
+	}
+	return !o.isRecursive(def)
+}
+
+// isRecursive tells if definition can be reached from itself in the graph of global references
+func (o *optimizer) isRecursive(def *Definition) bool {
+	if r, ok := o.recursive[def]; ok {
+		return r
+	}
+	visited := map[*Definition]struct{}{}
+	var reaches func(from *Definition) bool
+	reaches = func(from *Definition) bool {
+		for _, dep := range referencedDefinitions(from.body) {
+			if dep == def {
+				return true
+			}
+			if _, ok := visited[dep]; ok {
+				continue
+			}
+			visited[dep] = struct{}{}
+			if reaches(dep) {
+				return true
+			}
+		}
+		return false
+	}
+	r := reaches(def)
+	o.recursive[def] = r
+	return r
+}
+
+func referencedDefinitions(e Expression) (defs []*Definition) {
+	if e == nil {
+		return nil
+	}
+	switch x := e.(type) {
+	case *Global:
+		if x.definition != nil {
+			defs = append(defs, x.definition)
+		}
+	case *Update:
+		if x.definition != nil {
+			defs = append(defs, x.definition)
+		}
+	}
+	for _, child := range e.Children() {
+		if expr, ok := child.(Expression); ok {
+			defs = append(defs, referencedDefinitions(expr)...)
+		}
+	}
+	return
+}
+
+func referencesHidden(e Expression, moduleName ast.QualifiedIdentifier) bool {
+	if g, ok := e.(*Global); ok && g.moduleName == moduleName && g.definition != nil && g.definition.hidden {
+		return true
+	}
+	for _, child := range e.Children() {
+		if expr, ok := child.(Expression); ok && referencesHidden(expr, moduleName) {
+			return true
+		}
+	}
+	return false
+}
+
+// isInlinable tells if expression does not bind any locals except for arguments of inlined applications,
+// so it can be moved to other function as is
+func isInlinable(e Expression) bool {
+	switch x := e.(type) {
+	case *Const, *Local, *Global, *Apply, *Call, *Constructor, *Tuple, *List, *Record, *Access:
+	case *Let:
+		named, ok := x.pattern.(*PNamed)
+		if !ok || !strings.HasPrefix(string(named.name), inlineLocalPrefix) {
+			return false
+		}
+	case *Update:
+		if x.moduleName == "" {
+			return false
+		}
+	default:
+		return false
+	}
+	for _, child := range e.Children() {
+		if expr, ok := child.(Expression); ok && !isInlinable(expr) {
+			return false
+		}
+	}
+	return true
+}
+
+func expressionSize(e Expression) int {
+	size := 1
+	for _, child := range e.Children() {
+		if expr, ok := child.(Expression); ok {
+			size += expressionSize(expr)
+		}
+	}
+	return size
+}
+
+func countLocalUses(e Expression, name ast.Identifier) int {
+	if l, ok := e.(*Local); ok && l.name == name {
+		return 1
+	}
+	n := 0
+	for _, child := range e.Children() {
+		if expr, ok := child.(Expression); ok {
+			n += countLocalUses(expr, name)
+		}
+	}
+	return n
+}
+
+// substituteLocals makes a copy of inlinable expression with locals replaced by given expressions.
+// It returns false if expression contains nodes that cannot be inlined
+func substituteLocals(e Expression, replace map[ast.Identifier]Expression) (Expression, bool) {
+	sub := func(items []Expression) ([]Expression, bool) {
+		result := make([]Expression, len(items))
+		for i, item := range items {
+			var ok bool
+			if result[i], ok = substituteLocals(item, replace); !ok {
+				return nil, false
+			}
+		}
+		return result, true
+	}
+	subFields := func(fields []*RecordField) ([]*RecordField, bool) {
+		result := make([]*RecordField, len(fields))
+		for i, f := range fields {
+			value, ok := substituteLocals(f.value, replace)
+			if !ok {
+				return nil, false
+			}
+			result[i] = &RecordField{
+				location: f.location,
+				type_:    f.type_,
+				name:     f.name,
+				value:    value,
+			}
+		}
+		return result, true
+	}
+	base := func(x *expressionBase) *expressionBase {
+		return &expressionBase{location: x.location, type_: x.type_}
+	}
+
+	switch x := e.(type) {
+	case *Const:
+		return &Const{expressionBase: base(x.expressionBase), value: x.value}, true
+	case *Local:
+		if r, ok := replace[x.name]; ok {
+			if isInlinable(r) {
+				return substituteLocals(r, nil)
+			}
+			return r, true
+		}
+		return &Local{expressionBase: base(x.expressionBase), name: x.name, target: x.target}, true
+	case *Global:
+		return &Global{
+			expressionBase: base(x.expressionBase),
+			moduleName:     x.moduleName,
+			definitionName: x.definitionName,
+			definition:     x.definition,
+		}, true
+	case *Apply:
+		func_, ok := substituteLocals(x.func_, replace)
+		if !ok {
+			return nil, false
+		}
+		args, ok := sub(x.args)
+		if !ok {
+			return nil, false
+		}
+		return &Apply{expressionBase: base(x.expressionBase), func_: func_, args: args}, true
+	case *Call:
+		args, ok := sub(x.args)
+		if !ok {
+			return nil, false
+		}
+		return &Call{expressionBase: base(x.expressionBase), name: x.name, args: args}, true
+	case *Constructor:
+		args, ok := sub(x.args)
+		if !ok {
+			return nil, false
+		}
+		return &Constructor{
+			expressionBase: base(x.expressionBase),
+			dataName:       x.dataName,
+			optionName:     x.optionName,
+			dataType:       x.dataType,
+			args:           args,
+		}, true
+	case *Tuple:
+		items, ok := sub(x.items)
+		if !ok {
+			return nil, false
+		}
+		return &Tuple{expressionBase: base(x.expressionBase), items: items}, true
+	case *List:
+		items, ok := sub(x.items)
+		if !ok {
+			return nil, false
+		}
+		return &List{expressionBase: base(x.expressionBase), items: items, itemType: x.itemType}, true
+	case *Record:
+		fields, ok := subFields(x.fields)
+		if !ok {
+			return nil, false
+		}
+		return &Record{expressionBase: base(x.expressionBase), fields: fields}, true
+	case *Let:
+		value, ok := substituteLocals(x.value, replace)
+		if !ok {
+			return nil, false
+		}
+		body, ok := substituteLocals(x.body, replace)
+		if !ok {
+			return nil, false
+		}
+		return &Let{expressionBase: base(x.expressionBase), pattern: x.pattern, value: value, body: body}, true
+	case *Access:
+		record, ok := substituteLocals(x.record, replace)
+		if !ok {
+			return nil, false
+		}
+		return &Access{expressionBase: base(x.expressionBase), fieldName: x.fieldName, record: record}, true
+	case *Update:
+		fields, ok := subFields(x.fields)
+		if !ok {
+			return nil, false
+		}
+		return &Update{
+			expressionBase: base(x.expressionBase),
+			recordName:     x.recordName,
+			target:         x.target,
+			moduleName:     x.moduleName,
+			definition:     x.definition,
+			fields:         fields,
+		}, true
+	default:
+		return nil, false
+	}
+}
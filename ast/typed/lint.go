@@ -0,0 +1,117 @@
+package typed
+
+import (
+	"github.com/nar-lang/nar-compiler/ast"
+	"github.com/nar-lang/nar-compiler/common"
+	"strings"
+)
+
+type binding struct {
+	name     ast.Identifier
+	location ast.Location
+}
+
+// Lint returns warnings about unused let bindings and function parameters and shadowed names.
+// Names started with underscore are not reported.
+func (module *Module) Lint() (warnings []error) {
+	for _, def := range module.definitions {
+		warnings = append(warnings, def.lint()...)
+	}
+	return
+}
+
+func (def *Definition) lint() (warnings []error) {
+	if def.body == nil {
+		return nil
+	}
+	scope := map[ast.Identifier]struct{}{}
+	for _, param := range def.params {
+		for _, b := range patternBindings(param) {
+			if !usesLocal(def.body, b.name) {
+				warnings = append(warnings, common.NewErrorAt(b.location, "parameter `%s` is not used", b.name))
+			}
+			scope[b.name] = struct{}{}
+		}
+	}
+	return lintExpression(def.body, scope, warnings)
+}
+
+func lintExpression(e Expression, scope map[ast.Identifier]struct{}, warnings []error) []error {
+	switch x := e.(type) {
+	case *Let:
+		warnings = lintExpression(x.value, scope, warnings)
+		bindings := patternBindings(x.pattern)
+		for _, b := range bindings {
+			if !usesLocal(x.body, b.name) {
+				warnings = append(warnings, common.NewErrorAt(b.location, "local `%s` is not used", b.name))
+			}
+		}
+		return lintExpression(x.body, lintScope(scope, bindings, &warnings), warnings)
+	case *Select:
+		warnings = lintExpression(x.condition, scope, warnings)
+		for _, cs := range x.cases {
+			warnings = lintExpression(cs.expression, lintScope(scope, patternBindings(cs.pattern), &warnings), warnings)
+		}
+		return warnings
+	}
+	for _, child := range e.Children() {
+		if expr, ok := child.(Expression); ok {
+			warnings = lintExpression(expr, scope, warnings)
+		}
+	}
+	return warnings
+}
+
+// lintScope returns scope extended with bindings and reports bindings that shadow names of the scope
+func lintScope(scope map[ast.Identifier]struct{}, bindings []binding, warnings *[]error) map[ast.Identifier]struct{} {
+	if len(bindings) == 0 {
+		return scope
+	}
+	nested := make(map[ast.Identifier]struct{}, len(scope)+len(bindings))
+	for name := range scope {
+		nested[name] = struct{}{}
+	}
+	for _, b := range bindings {
+		if _, ok := scope[b.name]; ok {
+			*warnings = append(*warnings, common.NewErrorAt(b.location, "`%s` shadows the name declared above", b.name))
+		}
+		nested[b.name] = struct{}{}
+	}
+	return nested
+}
+
+// patternBindings returns locals declared by pattern except ones started with underscore
+func patternBindings(p Pattern) (bindings []binding) {
+	add := func(name ast.Identifier, loc ast.Location) {
+		if !strings.HasPrefix(string(name), "_") {
+			bindings = append(bindings, binding{name: name, location: loc})
+		}
+	}
+	switch x := p.(type) {
+	case *PNamed:
+		add(x.name, x.location)
+	case *PAlias:
+		add(x.alias, x.location)
+		bindings = append(bindings, patternBindings(x.nested)...)
+	case *PRecord:
+		for _, f := range x.fields {
+			add(f.name, f.location)
+		}
+	case *PCons:
+		bindings = append(bindings, patternBindings(x.head)...)
+		bindings = append(bindings, patternBindings(x.tail)...)
+	case *PList:
+		for _, item := range x.items {
+			bindings = append(bindings, patternBindings(item)...)
+		}
+	case *PTuple:
+		for _, item := range x.items {
+			bindings = append(bindings, patternBindings(item)...)
+		}
+	case *POption:
+		for _, arg := range x.args {
+			bindings = append(bindings, patternBindings(arg)...)
+		}
+	}
+	return
+}
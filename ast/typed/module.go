@@ -68,6 +68,14 @@ func (module *Module) CheckPatterns() (errors []error) {
 	return
 }
 
+// Optimize folds constant expressions, inlines small definitions and removes unreachable branches and unused bindings
+func (module *Module) Optimize() {
+	o := newOptimizer(module.name)
+	for _, def := range module.definitions {
+		def.optimize(o)
+	}
+}
+
 func (module *Module) Compose(
 	modules map[ast.QualifiedIdentifier]*Module, debug bool, binary *bytecode.Binary, hash *bytecode.BinaryHash,
 ) error {
@@ -0,0 +1,244 @@
+package typed
+
+import (
+	"github.com/nar-lang/nar-compiler/ast"
+	"github.com/nar-lang/nar-compiler/common"
+	"math"
+)
+
+type staticMatchResult int
+
+const (
+	staticMatchUnknown staticMatchResult = iota
+	staticMatchAlways
+	staticMatchNever
+)
+
+// foldMath replaces application of base math functions to constant arguments with its result.
+// Int and Float applications are folded separately by the type of the result, folds that overflow are skipped
+func foldMath(e *expressionBase, name ast.FullIdentifier, args []Expression) (Expression, bool) {
+	if name.Module() != common.NarBaseMathName {
+		return nil, false
+	}
+	definitionName := ast.Identifier(name[len(common.NarBaseMathName)+1:])
+	resultType, ok := e.type_.(*TNative)
+	if !ok {
+		return nil, false
+	}
+
+	var ints []int64
+	var floats []float64
+	for _, arg := range args {
+		c, ok := arg.(*Const)
+		if !ok {
+			return nil, false
+		}
+		switch v := c.value.(type) {
+		case ast.CInt:
+			ints = append(ints, v.Value)
+			floats = append(floats, float64(v.Value))
+		case ast.CFloat:
+			floats = append(floats, v.Value)
+		default:
+			return nil, false
+		}
+	}
+
+	var value ast.ConstValue
+	switch {
+	case resultType.name == common.NarBaseMathInt && len(ints) == len(args):
+		v, ok := foldInt(definitionName, ints)
+		if !ok {
+			return nil, false
+		}
+		value = ast.CInt{Value: v}
+	case resultType.name == common.NarBaseMathFloat:
+		v, ok := foldFloat(definitionName, floats)
+		if !ok {
+			return nil, false
+		}
+		value = ast.CFloat{Value: v}
+	default:
+		return nil, false
+	}
+
+	return &Const{
+		expressionBase: &expressionBase{location: e.location, type_: e.type_},
+		value:          value,
+	}, true
+}
+
+func foldInt(name ast.Identifier, args []int64) (int64, bool) {
+	switch {
+	case name == common.NarNegName && len(args) == 1:
+		if args[0] == math.MinInt64 {
+			return 0, false
+		}
+		return -args[0], true
+	case name == common.NarAddName && len(args) == 2:
+		a, b := args[0], args[1]
+		if (b > 0 && a > math.MaxInt64-b) || (b < 0 && a < math.MinInt64-b) {
+			return 0, false
+		}
+		return a + b, true
+	case name == common.NarSubName && len(args) == 2:
+		a, b := args[0], args[1]
+		if (b < 0 && a > math.MaxInt64+b) || (b > 0 && a < math.MinInt64+b) {
+			return 0, false
+		}
+		return a - b, true
+	case name == common.NarMulName && len(args) == 2:
+		a, b := args[0], args[1]
+		r := a * b
+		if a != 0 && (r/a != b || (a == -1 && b == math.MinInt64)) {
+			return 0, false
+		}
+		return r, true
+	}
+	return 0, false
+}
+
+func foldFloat(name ast.Identifier, args []float64) (float64, bool) {
+	var r float64
+	switch {
+	case name == common.NarNegName && len(args) == 1:
+		r = -args[0]
+	case name == common.NarAddName && len(args) == 2:
+		r = args[0] + args[1]
+	case name == common.NarSubName && len(args) == 2:
+		r = args[0] - args[1]
+	case name == common.NarMulName && len(args) == 2:
+		r = args[0] * args[1]
+	default:
+		return 0, false
+	}
+	if math.IsInf(r, 0) || math.IsNaN(r) {
+		return 0, false
+	}
+	return r, true
+}
+
+// isPure tells if expression can be removed when its value is not used:
+// it is a constant, a local, a reference to global or a closure (e.g. a lifted lambda)
+func isPure(e Expression) bool {
+	switch x := e.(type) {
+	case *Const, *Local, *Global:
+		return true
+	case *Apply:
+		g, ok := x.func_.(*Global)
+		if !ok || g.definition == nil || len(x.args) >= len(g.definition.params) {
+			return false
+		}
+		for _, arg := range x.args {
+			if !isPure(arg) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// staticMatch tells if pattern matches condition known at compile time
+func staticMatch(pattern Pattern, condition Expression) staticMatchResult {
+	switch p := pattern.(type) {
+	case *PAny, *PNamed:
+		return staticMatchAlways
+	case *PAlias:
+		return staticMatch(p.nested, condition)
+	case *PConst:
+		c, ok := condition.(*Const)
+		if !ok || !sameConstKind(c.value, p.value) {
+			return staticMatchUnknown
+		}
+		if c.value == p.value {
+			return staticMatchAlways
+		}
+		return staticMatchNever
+	case *POption:
+		ctor := constantConstructor(condition)
+		if ctor == nil {
+			return staticMatchUnknown
+		}
+		optionCtor, ok := p.definition.body.(*Constructor)
+		if !ok || optionCtor.dataName != ctor.dataName {
+			return staticMatchUnknown
+		}
+		if optionCtor.optionName != ctor.optionName {
+			return staticMatchNever
+		}
+		if len(p.args) == 0 {
+			return staticMatchAlways
+		}
+		return staticMatchUnknown
+	default:
+		return staticMatchUnknown
+	}
+}
+
+func sameConstKind(a, b ast.ConstValue) bool {
+	switch a.(type) {
+	case ast.CInt:
+		_, ok := b.(ast.CInt)
+		return ok
+	case ast.CFloat:
+		_, ok := b.(ast.CFloat)
+		return ok
+	case ast.CChar:
+		_, ok := b.(ast.CChar)
+		return ok
+	case ast.CString:
+		_, ok := b.(ast.CString)
+		return ok
+	case ast.CUnit:
+		_, ok := b.(ast.CUnit)
+		return ok
+	}
+	return false
+}
+
+// constantConstructor returns constructor the expression evaluates to if it is known at compile time
+func constantConstructor(e Expression) *Constructor {
+	switch x := e.(type) {
+	case *Constructor:
+		return x
+	case *Global:
+		if x.definition == nil || len(x.definition.params) > 0 {
+			return nil
+		}
+		if ctor, ok := x.definition.body.(*Constructor); ok && len(ctor.args) == 0 {
+			return ctor
+		}
+	}
+	return nil
+}
+
+// isBindingFree tells if pattern does not introduce any locals
+func isBindingFree(pattern Pattern) bool {
+	switch p := pattern.(type) {
+	case *PAny, *PConst:
+		return true
+	case *POption:
+		return len(p.args) == 0
+	default:
+		return false
+	}
+}
+
+// usesLocal tells if expression references local with given name
+func usesLocal(e Expression, name ast.Identifier) bool {
+	switch x := e.(type) {
+	case *Local:
+		return x.name == name
+	case *Update:
+		if x.moduleName == "" && x.recordName == name {
+			return true
+		}
+	}
+	for _, child := range e.Children() {
+		if expr, ok := child.(Expression); ok && usesLocal(expr, name) {
+			return true
+		}
+	}
+	return false
+}
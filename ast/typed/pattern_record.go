@@ -73,7 +73,7 @@ func (p *PRecord) appendEquations(eqs Equations, loc *ast.Location, localDefs lo
 		fields[f.name] = f.type_
 	}
 
-	typeRecord := NewTRecord(p.location, fields, true)
+	typeRecord := NewTRecord(p.location, fields, ctx.newTypeAnnotation(p))
 	eqs = append(eqs, NewEquation(p, p.type_, typeRecord))
 
 	if p.declaredType != nil {
@@ -49,7 +49,7 @@ func (ctx *SolvingContext) newAnnotatedConstraint(stmt annotationSource, predece
 	index := uint64(len(ctx.annotations))
 	ctx.annotations = append(ctx.annotations, stmt)
 	type_ := newTUnbound(stmt.Location(), predecessor, index, constraint, name)
-	tg, _ := newTypeGroup(nil, type_, stmt.Location())
+	tg, _ := newTypeGroup(ctx, nil, type_, stmt.Location())
 	ctx.groups = append(ctx.groups, tg)
 
 	return type_
@@ -106,7 +106,7 @@ type typeGroup struct {
 
 var lastGroupId = uint64(0)
 
-func newTypeGroup(type_ Type, ub *TUnbound, loc ast.Location) (*typeGroup, error) {
+func newTypeGroup(ctx *SolvingContext, type_ Type, ub *TUnbound, loc ast.Location) (*typeGroup, error) {
 	lastGroupId++
 
 	tg := &typeGroup{
@@ -121,7 +121,7 @@ func newTypeGroup(type_ Type, ub *TUnbound, loc ast.Location) (*typeGroup, error
 		err := tg.absorb(tub, loc)
 		return tg, err
 	} else if type_ != nil {
-		_, err := tg.specialize(type_, loc)
+		_, err := tg.specialize(ctx, type_, loc)
 		return tg, err
 	}
 	return tg, nil
@@ -150,7 +150,7 @@ func (tg *typeGroup) absorb(ub *TUnbound, loc ast.Location) error {
 	return nil
 }
 
-func (tg *typeGroup) merge(rg *typeGroup, loc ast.Location) (Equations, error) {
+func (tg *typeGroup) merge(ctx *SolvingContext, rg *typeGroup, loc ast.Location) (Equations, error) {
 	for ub := range rg.unbound {
 		tg.unbound[ub] = struct{}{}
 	}
@@ -162,12 +162,12 @@ func (tg *typeGroup) merge(rg *typeGroup, loc ast.Location) (Equations, error) {
 		tg.constraint = rg.constraint
 	}
 	if rg.specific != nil {
-		return tg.specialize(rg.specific, loc)
+		return tg.specialize(ctx, rg.specific, loc)
 	}
 	return nil, nil
 }
 
-func (tg *typeGroup) specialize(type_ Type, loc ast.Location) (Equations, error) {
+func (tg *typeGroup) specialize(ctx *SolvingContext, type_ Type, loc ast.Location) (Equations, error) {
 	switch tg.constraint {
 	case common.ConstraintNumber:
 		if n, ok := type_.(*TNative); !ok || (n.name != common.NarBaseMathInt && n.name != common.NarBaseMathFloat) {
@@ -179,7 +179,7 @@ func (tg *typeGroup) specialize(type_ Type, loc ast.Location) (Equations, error)
 		tg.specific = type_
 		return nil, nil
 	}
-	return tg.specific.merge(type_, loc)
+	return tg.specific.merge(ctx, type_, loc)
 }
 
 func (ctx *SolvingContext) insertAll(eqs Equations) (Equations, error) {
@@ -225,14 +225,14 @@ func (ctx *SolvingContext) insert(eq Equation) (Equations, error) {
 	} else if rIsUb {
 		return ctx.specialize(rUb, eq.left, eq.stmt.Location())
 	} else {
-		return eq.left.merge(eq.right, eq.stmt.Location())
+		return eq.left.merge(ctx, eq.right, eq.stmt.Location())
 	}
 }
 
 func (ctx *SolvingContext) specialize(ub *TUnbound, type_ Type, loc ast.Location) (Equations, error) {
 	for _, tg := range ctx.groups {
 		if tg.containsUnbound(ub) {
-			return tg.specialize(type_, loc)
+			return tg.specialize(ctx, type_, loc)
 		}
 	}
 	return nil, common.NewErrorAt(ub.location, "cannot find annotation of `%s`", ub.Code(""))
@@ -249,7 +249,7 @@ func (ctx *SolvingContext) merge(l, r *TUnbound, loc ast.Location) (Equations, e
 				}
 				tgb := ctx.groups[j]
 				if tgb.containsUnbound(r) {
-					esq, err := tga.merge(tgb, loc)
+					esq, err := tga.merge(ctx, tgb, loc)
 					if err != nil {
 						return nil, err
 					}
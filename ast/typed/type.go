@@ -10,7 +10,7 @@ type Type interface {
 	bytecoder
 	_type()
 	EqualsTo(other Type, req map[ast.FullIdentifier]struct{}) bool
-	merge(other Type, loc ast.Location) (Equations, error)
+	merge(ctx *SolvingContext, other Type, loc ast.Location) (Equations, error)
 	mapTo(subst map[uint64]Type) (Type, error)
 	makeUnique(ctx *SolvingContext, ubMap map[uint64]uint64) Type
 }
@@ -27,7 +27,7 @@ func (t *TData) makeUnique(ctx *SolvingContext, ubMap map[uint64]uint64) Type {
 	return NewTData(t.location, t.name, common.Map(func(x Type) Type { return x.makeUnique(ctx, ubMap) }, t.args), t.options)
 }
 
-func (t *TData) merge(other Type, loc ast.Location) (Equations, error) {
+func (t *TData) merge(ctx *SolvingContext, other Type, loc ast.Location) (Equations, error) {
 	if o, ok := other.(*TData); ok {
 		if o.name == t.name {
 			if len(t.args) == len(o.args) {
@@ -24,7 +24,7 @@ func (t *TFunc) makeUnique(ctx *SolvingContext, ubMap map[uint64]uint64) Type {
 	return NewTFunc(t.location, common.Map(func(x Type) Type { return x.makeUnique(ctx, ubMap) }, t.params), t.return_.makeUnique(ctx, ubMap))
 }
 
-func (t *TFunc) merge(other Type, loc ast.Location) (Equations, error) {
+func (t *TFunc) merge(ctx *SolvingContext, other Type, loc ast.Location) (Equations, error) {
 	t1 := t
 	if t2, ok := other.(*TFunc); ok {
 		if len(t1.params) < len(t2.params) {
@@ -25,7 +25,7 @@ func (t *TNative) makeUnique(ctx *SolvingContext, ubMap map[uint64]uint64) Type
 	return NewTNative(t.location, t.name, common.Map(func(x Type) Type { return x.makeUnique(ctx, ubMap) }, t.args))
 }
 
-func (t *TNative) merge(other Type, loc ast.Location) (Equations, error) {
+func (t *TNative) merge(ctx *SolvingContext, other Type, loc ast.Location) (Equations, error) {
 	if o, ok := other.(*TNative); ok {
 		if o.name == t.name {
 			if len(t.args) == len(o.args) {
@@ -4,20 +4,23 @@ import (
 	"fmt"
 	"github.com/nar-lang/nar-compiler/ast"
 	"github.com/nar-lang/nar-compiler/common"
+	"slices"
 	"strings"
 )
 
+// TRecord is a record type. If row is not nil the record is extensible:
+// row stands for all the fields that are not listed in fields.
 type TRecord struct {
 	*typeBase
-	fields            map[ast.Identifier]Type
-	mayHaveMoreFields bool
+	fields map[ast.Identifier]Type
+	row    Type
 }
 
-func NewTRecord(loc ast.Location, fields map[ast.Identifier]Type, mayHaveMoreFields bool) Type {
+func NewTRecord(loc ast.Location, fields map[ast.Identifier]Type, row Type) Type {
 	return &TRecord{
-		typeBase:          newTypeBase(loc),
-		fields:            fields,
-		mayHaveMoreFields: mayHaveMoreFields,
+		typeBase: newTypeBase(loc),
+		fields:   fields,
+		row:      row,
 	}
 }
 
@@ -26,27 +29,64 @@ func (t *TRecord) makeUnique(ctx *SolvingContext, ubMap map[uint64]uint64) Type
 	for n, f := range t.fields {
 		nf[n] = f.makeUnique(ctx, ubMap)
 	}
-	return NewTRecord(t.location, nf, t.mayHaveMoreFields)
+	var row Type
+	if t.row != nil {
+		row = t.row.makeUnique(ctx, ubMap)
+	}
+	return NewTRecord(t.location, nf, row)
 }
 
-func (t *TRecord) merge(other Type, loc ast.Location) (Equations, error) {
-	if o, ok := other.(*TRecord); ok {
-		var eqs Equations
-		for n, f := range t.fields {
-			if of, ok := o.fields[n]; ok {
-				eqs = append(eqs, NewEquationBestLoc(f, of, loc))
-			} else if !o.mayHaveMoreFields {
-				return nil, common.NewErrorAt(loc, "record missing field `%s`", n)
-			}
+func (t *TRecord) merge(ctx *SolvingContext, other Type, loc ast.Location) (Equations, error) {
+	o, ok := other.(*TRecord)
+	if !ok {
+		return nil, newTypeMatchError(loc, t, other)
+	}
+
+	var eqs Equations
+	onlyT := map[ast.Identifier]Type{}
+	onlyO := map[ast.Identifier]Type{}
+	for n, f := range t.fields {
+		if of, ok := o.fields[n]; ok {
+			eqs = append(eqs, NewEquationBestLoc(f, of, loc))
+		} else if o.row == nil {
+			return nil, common.NewErrorAt(loc, "record missing field `%s`", n)
+		} else {
+			onlyT[n] = f
 		}
-		for n := range o.fields {
-			if _, ok := t.fields[n]; !ok && !t.mayHaveMoreFields {
+	}
+	for n, f := range o.fields {
+		if _, ok := t.fields[n]; !ok {
+			if t.row == nil {
 				return nil, common.NewErrorAt(loc, "record missing field `%s`", n)
 			}
+			onlyO[n] = f
+		}
+	}
+
+	switch {
+	case t.row == nil && o.row == nil:
+		break
+	case o.row == nil:
+		eqs = append(eqs, NewEquationBestLoc(t.row, NewTRecord(loc, onlyO, nil), loc))
+	case t.row == nil:
+		eqs = append(eqs, NewEquationBestLoc(o.row, NewTRecord(loc, onlyT, nil), loc))
+	case t.row.EqualsTo(o.row, nil):
+		if len(onlyT) > 0 || len(onlyO) > 0 {
+			return nil, newTypeMatchError(loc, t, other)
 		}
-		return eqs, nil
+	case len(onlyT) == 0 && len(onlyO) == 0:
+		eqs = append(eqs, NewEquationBestLoc(t.row, o.row, loc))
+	case len(onlyT) == 0:
+		eqs = append(eqs, NewEquationBestLoc(t.row, NewTRecord(loc, onlyO, o.row), loc))
+	case len(onlyO) == 0:
+		eqs = append(eqs, NewEquationBestLoc(o.row, NewTRecord(loc, onlyT, t.row), loc))
+	default:
+		rest := ctx.newTypeAnnotation(t)
+		eqs = append(eqs,
+			NewEquationBestLoc(t.row, NewTRecord(loc, onlyO, rest), loc),
+			NewEquationBestLoc(o.row, NewTRecord(loc, onlyT, rest), loc))
 	}
-	return nil, newTypeMatchError(loc, t, other)
+	return eqs, nil
 }
 
 func (t *TRecord) mapTo(subst map[uint64]Type) (Type, error) {
@@ -57,6 +97,27 @@ func (t *TRecord) mapTo(subst map[uint64]Type) (Type, error) {
 			t.fields[n] = x
 		}
 	}
+	if t.row != nil {
+		row, err := t.row.mapTo(subst)
+		if err != nil {
+			return nil, err
+		}
+		switch row.(type) {
+		case *TUnbound:
+			t.row = row
+		case *TRecord:
+			//row is solved to the rest of the fields, flatten them into this record
+			ext := row.(*TRecord)
+			for n, f := range ext.fields {
+				if _, ok := t.fields[n]; !ok {
+					t.fields[n] = f
+				}
+			}
+			t.row = ext.row
+		default:
+			return nil, common.NewErrorOf(t, "record can only be extended with another record, got %s", row.Code(""))
+		}
+	}
 	return t, nil
 }
 
@@ -66,6 +127,12 @@ func (t *TRecord) EqualsTo(other Type, req map[ast.FullIdentifier]struct{}) bool
 		if len(t.fields) != len(ty.fields) {
 			return false
 		}
+		if (t.row == nil) != (ty.row == nil) {
+			return false
+		}
+		if t.row != nil && !t.row.EqualsTo(ty.row, req) {
+			return false
+		}
 		for n, fx := range t.fields {
 			if fy, ok := ty.fields[n]; !ok {
 				return false
@@ -79,19 +146,30 @@ func (t *TRecord) EqualsTo(other Type, req map[ast.FullIdentifier]struct{}) bool
 }
 
 func (t *TRecord) Children() []Statement {
-	return common.Map(func(x Type) Statement { return x }, common.Values(t.fields))
+	children := common.Map(func(x Type) Statement { return x }, common.Values(t.fields))
+	if t.row != nil {
+		children = append(children, t.row)
+	}
+	return children
 }
 
 func (t *TRecord) Code(currentModule ast.QualifiedIdentifier) string {
+	names := common.Keys(t.fields)
+	slices.Sort(names)
+
 	sb := strings.Builder{}
 	sb.WriteString("{")
-	c := len(t.fields)
-	for n, v := range t.fields {
-		sb.WriteString(fmt.Sprintf("%s:%s", n, v.Code("")))
-		c--
-		if c > 0 {
+	if t.row != nil {
+		sb.WriteString(t.row.Code(""))
+		if len(names) > 0 {
+			sb.WriteString(" | ")
+		}
+	}
+	for i, n := range names {
+		if i > 0 {
 			sb.WriteString(", ")
 		}
+		sb.WriteString(fmt.Sprintf("%s:%s", n, t.fields[n].Code("")))
 	}
 	sb.WriteString("}")
 	return sb.String()
@@ -100,3 +178,7 @@ func (t *TRecord) Code(currentModule ast.QualifiedIdentifier) string {
 func (t *TRecord) Fields() map[ast.Identifier]Type {
 	return t.fields
 }
+
+func (t *TRecord) Row() Type {
+	return t.row
+}
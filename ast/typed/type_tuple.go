@@ -22,7 +22,7 @@ func (t *TTuple) makeUnique(ctx *SolvingContext, ubMap map[uint64]uint64) Type {
 	return NewTTuple(t.location, common.Map(func(x Type) Type { return x.makeUnique(ctx, ubMap) }, t.items))
 }
 
-func (t *TTuple) merge(other Type, loc ast.Location) (Equations, error) {
+func (t *TTuple) merge(ctx *SolvingContext, other Type, loc ast.Location) (Equations, error) {
 	if o, ok := other.(*TTuple); ok {
 		if len(t.items) == len(o.items) {
 			var eqs Equations
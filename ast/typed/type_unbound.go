@@ -46,7 +46,7 @@ func (t *TUnbound) makeUnique(ctx *SolvingContext, ubMap map[uint64]uint64) Type
 	return ub
 }
 
-func (t *TUnbound) merge(other Type, loc ast.Location) (Equations, error) {
+func (t *TUnbound) merge(ctx *SolvingContext, other Type, loc ast.Location) (Equations, error) {
 	panic("should not be called")
 }
 
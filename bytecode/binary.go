@@ -10,7 +10,7 @@ import (
 	"strconv"
 )
 
-const Version uint32 = 100
+const Version uint32 = 105
 
 const signature = 'N'<<8 | 'A'<<16 | 'R'<<24
 
@@ -24,8 +24,9 @@ type Location struct {
 
 func NewBinary() *Binary {
 	return &Binary{
-		Exports:  map[FullIdentifier]Pointer{},
-		Packages: map[QualifiedIdentifier]uint32{},
+		Exports:       map[FullIdentifier]Pointer{},
+		Packages:      map[QualifiedIdentifier]string{},
+		PackageHashes: map[QualifiedIdentifier]string{},
 	}
 }
 
@@ -34,9 +35,11 @@ type Binary struct {
 	Funcs           []Func
 	Strings         []string
 	Consts          []PackedConst
+	JumpTables      []JumpTable
 	Exports         map[FullIdentifier]Pointer
 	Entry           FullIdentifier
-	Packages        map[QualifiedIdentifier]uint32
+	Packages        map[QualifiedIdentifier]string
+	PackageHashes   map[QualifiedIdentifier]string
 }
 
 type Func struct {
@@ -47,6 +50,17 @@ type Func struct {
 	Locations []Location
 }
 
+// JumpTable maps data option names to jump deltas of OpKindSwitch
+type JumpTable struct {
+	Cases   []JumpTableCase
+	Default int32
+}
+
+type JumpTableCase struct {
+	Option StringHash
+	Delta  int32
+}
+
 var stringEncoder = unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewEncoder()
 var stringDecoder = unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder()
 
@@ -84,6 +98,16 @@ func (b *Binary) Write(writer io.Writer, debug bool) (err error) {
 		w(uint64(c.Pack()))
 	}
 
+	w(uint32(len(b.JumpTables)))
+	for _, t := range b.JumpTables {
+		w(int32(t.Default))
+		w(uint32(len(t.Cases)))
+		for _, c := range t.Cases {
+			w(uint32(c.Option))
+			w(int32(c.Delta))
+		}
+	}
+
 	w(uint32(len(b.Funcs)))
 	for _, fn := range b.Funcs {
 		w(uint32(fn.Name))
@@ -121,7 +145,8 @@ func (b *Binary) Write(writer io.Writer, debug bool) (err error) {
 	w(uint32(len(b.Packages)))
 	for _, p := range packageNames {
 		ws(string(p))
-		w(int32(b.Packages[p]))
+		ws(b.Packages[p])
+		ws(b.PackageHashes[p])
 	}
 
 	return nil
@@ -186,6 +211,23 @@ func Read(reader io.Reader) (bin *Binary, err error) {
 		bin.Consts = append(bin.Consts, unpackConst(kind, packed))
 	}
 
+	var numJumpTables uint32
+	e(binary.Read(reader, order, &numJumpTables))
+	bin.JumpTables = make([]JumpTable, 0, numJumpTables)
+	for i := uint32(0); i < numJumpTables; i++ {
+		table := JumpTable{}
+		e(binary.Read(reader, order, &table.Default))
+		var numCases uint32
+		e(binary.Read(reader, order, &numCases))
+		for j := uint32(0); j < numCases; j++ {
+			c := JumpTableCase{}
+			e(binary.Read(reader, order, &c.Option))
+			e(binary.Read(reader, order, &c.Delta))
+			table.Cases = append(table.Cases, c)
+		}
+		bin.JumpTables = append(bin.JumpTables, table)
+	}
+
 	var numFuncs uint32
 	e(binary.Read(reader, order, &numFuncs))
 	bin.Funcs = make([]Func, 0, numFuncs)
@@ -237,13 +279,19 @@ func Read(reader io.Reader) (bin *Binary, err error) {
 
 	var numPackages uint32
 	e(binary.Read(reader, order, &numPackages))
-	bin.Packages = make(map[QualifiedIdentifier]uint32, numPackages)
+	bin.Packages = make(map[QualifiedIdentifier]string, numPackages)
+	bin.PackageHashes = make(map[QualifiedIdentifier]string, numPackages)
 	for i := uint32(0); i < numPackages; i++ {
 		name, err := rs(reader, order)
 		e(err)
-		var version uint32
-		e(binary.Read(reader, order, &version))
+		version, err := rs(reader, order)
+		e(err)
 		bin.Packages[QualifiedIdentifier(name)] = version
+		contentHash, err := rs(reader, order)
+		e(err)
+		if contentHash != "" {
+			bin.PackageHashes[QualifiedIdentifier(name)] = contentHash
+		}
 	}
 	return
 }
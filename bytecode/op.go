@@ -9,6 +9,7 @@ type ConstKind uint8
 type StackKind uint8
 type SwapPopMode uint8
 type ObjectKind uint8
+type TailApplyMode uint8
 
 const (
 	opKindNone OpKind = iota
@@ -19,13 +20,14 @@ const (
 	// OpKindLoadConst adds const value object to the top of the stack
 	OpKindLoadConst
 	// OpKindApply executes the function from the top of the stack.
-	// Arguments are taken from the top of the stack in reverse order
-	// (topmost object is the last arg). Returned value is left on the top of the stack.
+	// Arguments are evaluated in source order before the function object and are taken
+	// from the top of the stack in reverse order (topmost object is the last arg).
+	// Returned value is left on the top of the stack.
 	// In case of NumArgs is less than number of function parameters it creates
 	// a closure and leaves it on the top of the stack
 	OpKindApply
 	// OpKindCall executes native function.
-	// Arguments are taken from the top of the stack in reverse order
+	// Arguments are evaluated in source order and are taken from the top of the stack in reverse order
 	// (topmost object is last arg). Returned value is left on the top of the stack.
 	OpKindCall
 	// OpKindJump moves on delta ops unconditional
@@ -35,8 +37,10 @@ const (
 	// Matched object is left on the top of the stack in both cases
 	OpKindJump
 	// OpKindMakeObject creates an object on stack.
-	// List items stored on stack in reverse order (topmost object is the last item)
-	// Record fields stored as repeating pairs const string and value (field name is on the top of the stack)
+	// Items of all the objects are evaluated in source order, so they are stored on stack in reverse order.
+	// List and tuple items stored on stack in reverse order (topmost object is the last item)
+	// Record fields stored as repeating pairs value and const string in order of declaration
+	// (name of the last field is on the top of the stack)
 	// Data stores option name as const string on the top of the stack and
 	// args after that in reverse order (topmost is the last arg)
 	OpKindMakeObject
@@ -52,6 +56,22 @@ const (
 	// OpKindSwapPop if pop mode - removes topmost object from the stack
 	// if both mode - removes second object from the top of the stack
 	OpKindSwapPop
+	// OpKindTailApply executes function call in tail position reusing the frame of the current function.
+	// Arguments are taken from the top of the stack the same way as in OpKindApply.
+	// All other objects of the current function are removed from the stack and its locals are cleared.
+	// If apply mode - function is taken from the top of the stack (above the arguments),
+	// its returned value becomes the returned value of the current function.
+	// If jump mode - current function is called again: arguments are put back to the empty stack
+	// and execution moves on delta ops (to the beginning of the function)
+	OpKindTailApply
+	// OpKindSwitch takes data option name of the object on the top of the stack and moves on delta ops
+	// found for it in jump table of the binary with given index (default delta if name is not in the table).
+	// Object is left on the top of the stack
+	OpKindSwitch
+	// OpKindMakeClosure creates closure of the function with given pointer.
+	// Captured arguments are taken from the top of the stack the same way as in OpKindApply.
+	// Created closure is left on the top of the stack
+	OpKindMakeClosure
 )
 const (
 	patternKindNone PatternKind = iota
@@ -90,6 +110,11 @@ const (
 	SwapPopModeBoth
 	SwapPopModePop
 )
+const (
+	tailApplyModeNone TailApplyMode = iota
+	TailApplyModeApply
+	TailApplyModeJump
+)
 
 type Op uint64
 
@@ -230,3 +255,33 @@ func AppendSwapPop(loc Location, mode SwapPopMode,
 	return append(ops, buildOp(OpKindSwapPop, uint8(mode), 0, 0)),
 		append(locations, loc)
 }
+
+func AppendTailApply(numArgs uint8, loc Location, ops []Op, locations []Location,
+) ([]Op, []Location) {
+	return append(ops, buildOp(OpKindTailApply, numArgs, uint8(TailApplyModeApply), 0)),
+		append(locations, loc)
+}
+
+func AppendTailJump(numArgs uint8, jumpDelta int, loc Location, ops []Op, locations []Location,
+) ([]Op, []Location) {
+	return append(ops, buildOp(OpKindTailApply, numArgs, uint8(TailApplyModeJump), uint32(jumpDelta))),
+		append(locations, loc)
+}
+
+func AppendSwitch(
+	options []string, loc Location, ops []Op, locations []Location, binary *Binary, hash *BinaryHash,
+) ([]Op, []Location) {
+	table := JumpTable{}
+	for _, option := range options {
+		table.Cases = append(table.Cases, JumpTableCase{Option: hash.HashString(option, binary)})
+	}
+	binary.JumpTables = append(binary.JumpTables, table)
+	return append(ops, buildOp(OpKindSwitch, 0, 0, uint32(len(binary.JumpTables)-1))),
+		append(locations, loc)
+}
+
+func AppendMakeClosure(ptr Pointer, numArgs uint8, loc Location, ops []Op, locations []Location,
+) ([]Op, []Location) {
+	return append(ops, buildOp(OpKindMakeClosure, numArgs, 0, uint32(ptr))),
+		append(locations, loc)
+}
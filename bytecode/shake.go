@@ -0,0 +1,119 @@
+package bytecode
+
+import "fmt"
+
+// Shake removes functions that cannot be reached from given roots by walking global and closure references,
+// together with strings, consts and jump tables used only by removed functions.
+// Pointers, string and const hashes and jump table indices of remaining functions are renumbered.
+// Exports of removed functions are dropped. Empty string always stays at index 0.
+func (b *Binary) Shake(roots []FullIdentifier) error {
+	reachable := make([]bool, len(b.Funcs))
+	var queue []Pointer
+	for _, root := range roots {
+		ptr, ok := b.Exports[root]
+		if !ok {
+			return fmt.Errorf("definition `%s` is not exported", root)
+		}
+		if !reachable[ptr] {
+			reachable[ptr] = true
+			queue = append(queue, ptr)
+		}
+	}
+	for len(queue) > 0 {
+		ptr := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		for _, op := range b.Funcs[ptr].Ops {
+			if kind, _, _, a := op.Decompose(); (kind == OpKindLoadGlobal || kind == OpKindMakeClosure) && !reachable[a] {
+				reachable[a] = true
+				queue = append(queue, Pointer(a))
+			}
+		}
+	}
+
+	funcMap := make([]Pointer, len(b.Funcs))
+	stringMap := map[StringHash]StringHash{}
+	constMap := map[ConstHash]ConstHash{}
+	var funcs []Func
+	strings := []string{""}
+	var consts []PackedConst
+	var jumpTables []JumpTable
+
+	mapString := func(h StringHash) StringHash {
+		if b.Strings[h] == "" {
+			return 0
+		}
+		if x, ok := stringMap[h]; ok {
+			return x
+		}
+		x := StringHash(len(strings))
+		stringMap[h] = x
+		strings = append(strings, b.Strings[h])
+		return x
+	}
+	mapConst := func(h ConstHash) ConstHash {
+		if x, ok := constMap[h]; ok {
+			return x
+		}
+		x := ConstHash(len(consts))
+		constMap[h] = x
+		consts = append(consts, b.Consts[h])
+		return x
+	}
+
+	for i, fn := range b.Funcs {
+		if reachable[i] {
+			funcMap[i] = Pointer(len(funcs))
+			funcs = append(funcs, fn)
+		}
+	}
+
+	for i := range funcs {
+		fn := &funcs[i]
+		fn.Name = mapString(fn.Name)
+		ops := make([]Op, len(fn.Ops))
+		for j, op := range fn.Ops {
+			kind, x, y, a := op.Decompose()
+			switch kind {
+			case OpKindLoadGlobal, OpKindMakeClosure:
+				a = uint32(funcMap[a])
+			case OpKindLoadLocal, OpKindCall, OpKindAccess, OpKindUpdate:
+				a = uint32(mapString(StringHash(a)))
+			case OpKindLoadConst:
+				switch ConstKind(y) {
+				case ConstKindString:
+					a = uint32(mapString(StringHash(a)))
+				case ConstKindInt, ConstKindFloat:
+					a = uint32(mapConst(ConstHash(a)))
+				}
+			case OpKindSwitch:
+				table := b.JumpTables[a]
+				cases := make([]JumpTableCase, len(table.Cases))
+				for k, c := range table.Cases {
+					cases[k] = JumpTableCase{Option: mapString(c.Option), Delta: c.Delta}
+				}
+				a = uint32(len(jumpTables))
+				jumpTables = append(jumpTables, JumpTable{Cases: cases, Default: table.Default})
+			case OpKindMakePattern:
+				if PatternKind(x) != PatternKindList && PatternKind(x) != PatternKindRecord {
+					a = uint32(mapString(StringHash(a)))
+				}
+			}
+			ops[j] = buildOp(kind, x, y, a)
+		}
+		fn.Ops = ops
+	}
+
+	exports := map[FullIdentifier]Pointer{}
+	for name, ptr := range b.Exports {
+		if reachable[ptr] {
+			exports[name] = funcMap[ptr]
+		}
+	}
+
+	b.Funcs = funcs
+	b.Strings = strings
+	b.Consts = consts
+	b.JumpTables = jumpTables
+	b.Exports = exports
+	return nil
+}
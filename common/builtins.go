@@ -14,16 +14,22 @@ const (
 var (
 	NarBaseBasicsName = ast.QualifiedIdentifier("Nar.Base.Basics")
 	NarBaseMathName   = ast.QualifiedIdentifier("Nar.Base.Math")
+	NarBaseStringName = ast.QualifiedIdentifier("Nar.Base.String")
 
-	NarTrueName  = ast.Identifier("True")
-	NarFalseName = ast.Identifier("False")
-	NarNegName   = ast.Identifier("neg")
+	NarTrueName   = ast.Identifier("True")
+	NarFalseName  = ast.Identifier("False")
+	NarNegName    = ast.Identifier("neg")
+	NarAddName    = ast.Identifier("add")
+	NarSubName    = ast.Identifier("sub")
+	NarMulName    = ast.Identifier("mul")
+	NarAppendName = ast.Identifier("append")
+	NarShowName   = ast.Identifier("show")
 
 	NarBaseCharChar     = MakeFullIdentifier("Nar.Base.Char", "Char")
 	NarBaseMathInt      = MakeFullIdentifier(NarBaseMathName, "Int")
 	NarBaseMathFloat    = MakeFullIdentifier(NarBaseMathName, "Float")
 	NarBaseBasicsUnit   = MakeFullIdentifier(NarBaseBasicsName, "Unit")
-	NarBaseStringString = MakeFullIdentifier("Nar.Base.String", "String")
+	NarBaseStringString = MakeFullIdentifier(NarBaseStringName, "String")
 	NarBaseListList     = MakeFullIdentifier("Nar.Base.List", "List")
 	NarBaseBasicsBool   = MakeFullIdentifier(NarBaseBasicsName, "Bool")
 )
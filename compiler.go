@@ -8,7 +8,9 @@ import (
 	"github.com/nar-lang/nar-compiler/common"
 	"github.com/nar-lang/nar-compiler/locator"
 	"github.com/nar-lang/nar-compiler/logger"
+	"path/filepath"
 	"slices"
+	"strings"
 )
 
 func Compile(
@@ -21,20 +23,35 @@ func Compile(
 	affectedModules := map[ast.QualifiedIdentifier]struct{}{}
 
 	for _, pkg := range packages {
-		sourceMap := pkg.Sources()
-		keys := common.Keys(sourceMap)
-		slices.Sort(keys)
-
-		for _, path := range keys {
+		for _, source := range pkg.Sources() {
+			path := source.FilePath()
+			reloaded, err := source.Reload()
+			if err != nil {
+				log.Err(err)
+				continue
+			}
 			var parsedModule *parsed.Module
 			for _, m := range parsedModules {
 				if m.Location().FilePath() == path {
 					parsedModule = m
 				}
 			}
+			if parsedModule != nil && reloaded {
+				delete(parsedModules, parsedModule.Name())
+				invalidateModule(parsedModule.Name(), normalizedModules, typedModules)
+				parsedModule = nil
+			}
 			if parsedModule == nil {
+				content, err := source.Content()
+				if err != nil {
+					log.Err(err)
+					continue
+				}
+				for _, w := range source.Warnings() {
+					log.Warn(w)
+				}
 				var errors []error
-				parsedModule, errors = Parse(path, sourceMap[path])
+				parsedModule, errors = Parse(path, content)
 				for _, e := range errors {
 					log.Err(e)
 				}
@@ -42,6 +59,10 @@ func Compile(
 					continue
 				}
 				parsedModule.SetPackageName(ast.PackageIdentifier(pkg.Info().Name))
+				if expected, ok := moduleNameOfPath(pkg, path); ok && expected != parsedModule.Name() {
+					log.Warn(common.NewErrorOf(parsedModule,
+						"module `%s` is declared in file that corresponds to module `%s`", parsedModule.Name(), expected))
+				}
 
 				referencedPackages := map[ast.PackageIdentifier]struct{}{}
 				for p := range pkg.Info().Dependencies {
@@ -112,3 +133,56 @@ func Compile(
 
 	return
 }
+
+// Lint reports warnings about given modules that belong to one of the packages.
+// Modules are expected to be compiled without errors
+func Lint(
+	log *logger.LogWriter,
+	packageNames []ast.PackageIdentifier,
+	moduleNames []ast.QualifiedIdentifier,
+	parsedModules map[ast.QualifiedIdentifier]*parsed.Module,
+	typedModules map[ast.QualifiedIdentifier]*typed.Module,
+) {
+	for _, name := range moduleNames {
+		parsedModule, ok := parsedModules[name]
+		if !ok || !slices.Contains(packageNames, parsedModule.PackageName()) {
+			continue
+		}
+		for _, w := range parsedModule.Lint() {
+			log.Warn(w)
+		}
+		if typedModule, ok := typedModules[name]; ok {
+			for _, w := range typedModule.Lint() {
+				log.Warn(w)
+			}
+		}
+	}
+}
+
+// invalidateModule removes compiled module and modules that depend on it from caches
+func invalidateModule(
+	name ast.QualifiedIdentifier,
+	normalizedModules map[ast.QualifiedIdentifier]*normalized.Module,
+	typedModules map[ast.QualifiedIdentifier]*typed.Module,
+) {
+	delete(normalizedModules, name)
+	delete(typedModules, name)
+	for depName, m := range normalizedModules {
+		if slices.Contains(m.Dependencies(), name) {
+			invalidateModule(depName, normalizedModules, typedModules)
+		}
+	}
+}
+
+// moduleNameOfPath returns module name that corresponds to the source file path relative to the package `src` directory
+func moduleNameOfPath(pkg locator.Package, path string) (ast.QualifiedIdentifier, bool) {
+	if pkg.Path() == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(filepath.Join(pkg.Path(), "src"), path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", false
+	}
+	rel = strings.TrimSuffix(filepath.ToSlash(rel), ".nar")
+	return ast.QualifiedIdentifier(strings.ReplaceAll(rel, "/", ".")), true
+}
@@ -0,0 +1,211 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/nar-lang/nar-compiler/bytecode"
+)
+
+// disassemble returns text representation of ops of exported definition of module `Test`
+func (r testResult) disassemble(t *testing.T, name string) string {
+	t.Helper()
+	ptr, ok := r.bin.Exports[bytecode.FullIdentifier("Test."+name)]
+	if !ok {
+		t.Fatalf("definition `Test.%s` is not exported", name)
+	}
+	str := func(a uint32) string { return r.bin.Strings[a] }
+	funcName := func(a uint32) string { return str(uint32(r.bin.Funcs[a].Name)) }
+	objectKinds := map[bytecode.ObjectKind]string{
+		bytecode.ObjectKindList: "List", bytecode.ObjectKindTuple: "Tuple",
+		bytecode.ObjectKindRecord: "Record", bytecode.ObjectKindOption: "Option",
+	}
+	patternKinds := map[bytecode.PatternKind]string{
+		bytecode.PatternKindAlias: "Alias", bytecode.PatternKindAny: "Any", bytecode.PatternKindCons: "Cons",
+		bytecode.PatternKindConst: "Const", bytecode.PatternKindDataOption: "Option", bytecode.PatternKindList: "List",
+		bytecode.PatternKindNamed: "Named", bytecode.PatternKindRecord: "Record", bytecode.PatternKindTuple: "Tuple",
+	}
+
+	sb := strings.Builder{}
+	for _, op := range r.bin.Funcs[ptr].Ops {
+		kind, b, c, a := op.Decompose()
+		switch kind {
+		case bytecode.OpKindLoadLocal:
+			fmt.Fprintf(&sb, "LoadLocal %s", str(a))
+		case bytecode.OpKindLoadGlobal:
+			fmt.Fprintf(&sb, "LoadGlobal %s", funcName(a))
+		case bytecode.OpKindLoadConst:
+			switch bytecode.ConstKind(c) {
+			case bytecode.ConstKindUnit:
+				sb.WriteString("LoadConst ()")
+			case bytecode.ConstKindChar:
+				fmt.Fprintf(&sb, "LoadConst %q", rune(a))
+			case bytecode.ConstKindInt:
+				fmt.Fprintf(&sb, "LoadConst %d", r.bin.Consts[a].Int())
+			case bytecode.ConstKindFloat:
+				fmt.Fprintf(&sb, "LoadConst %g", r.bin.Consts[a].Float())
+			case bytecode.ConstKindString:
+				fmt.Fprintf(&sb, "LoadConst %q", str(a))
+			}
+		case bytecode.OpKindApply:
+			fmt.Fprintf(&sb, "Apply %d", b)
+		case bytecode.OpKindCall:
+			fmt.Fprintf(&sb, "Call %s %d", str(a), b)
+		case bytecode.OpKindJump:
+			if b == 0 {
+				fmt.Fprintf(&sb, "Jump %d", int32(a))
+			} else {
+				fmt.Fprintf(&sb, "Match %d", int32(a))
+			}
+		case bytecode.OpKindMakeObject:
+			fmt.Fprintf(&sb, "MakeObject %s %d", objectKinds[bytecode.ObjectKind(b)], a)
+		case bytecode.OpKindMakePattern:
+			switch k := bytecode.PatternKind(b); k {
+			case bytecode.PatternKindList, bytecode.PatternKindRecord:
+				fmt.Fprintf(&sb, "MakePattern %s %d", patternKinds[k], a)
+			case bytecode.PatternKindAlias, bytecode.PatternKindNamed:
+				fmt.Fprintf(&sb, "MakePattern %s %s", patternKinds[k], str(a))
+			case bytecode.PatternKindDataOption:
+				fmt.Fprintf(&sb, "MakePattern %s %s %d", patternKinds[k], str(a), c)
+			default:
+				fmt.Fprintf(&sb, "MakePattern %s %d", patternKinds[k], c)
+			}
+		case bytecode.OpKindAccess:
+			fmt.Fprintf(&sb, "Access %s", str(a))
+		case bytecode.OpKindUpdate:
+			fmt.Fprintf(&sb, "Update %s", str(a))
+		case bytecode.OpKindSwapPop:
+			if bytecode.SwapPopMode(b) == bytecode.SwapPopModeBoth {
+				sb.WriteString("SwapPop Both")
+			} else {
+				sb.WriteString("SwapPop Pop")
+			}
+		case bytecode.OpKindTailApply:
+			if bytecode.TailApplyMode(c) == bytecode.TailApplyModeJump {
+				fmt.Fprintf(&sb, "TailJump %d %d", b, int32(a))
+			} else {
+				fmt.Fprintf(&sb, "TailApply %d", b)
+			}
+		case bytecode.OpKindSwitch:
+			table := r.bin.JumpTables[a]
+			sb.WriteString("Switch")
+			for _, cs := range table.Cases {
+				fmt.Fprintf(&sb, " %s:%d", str(uint32(cs.Option)), cs.Delta)
+			}
+			fmt.Fprintf(&sb, " _:%d", table.Default)
+		case bytecode.OpKindMakeClosure:
+			fmt.Fprintf(&sb, "MakeClosure %s %d", funcName(a), b)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// expectBytecode compares disassembled definition with expected lines
+func (r testResult) expectBytecode(t *testing.T, name string, expected string) {
+	t.Helper()
+	expected = strings.TrimLeft(expected, "\n")
+	if got := r.disassemble(t, name); got != expected {
+		t.Errorf("bytecode of %s:\nexpected:\n%s\ngot:\n%s", name, expected, got)
+	}
+}
+
+func TestCompositeExpressionsSourceOrder(t *testing.T) {
+	r := mustCompile(t, `
+module Test
+
+import Nar.Base.String exposing *
+
+type Pair = P(String, String, String)
+
+def list = [fromInt(1), fromInt(2), fromInt(3)]
+def tuple = (fromInt(1), fromInt(2), fromInt(3))
+def record = { c = fromInt(1), a = fromInt(2), b = fromInt(3) }
+def constructor = P(fromInt(1), fromInt(2), fromInt(3))
+`, false)
+
+	r.expectBytecode(t, "list", `
+LoadConst 1
+LoadGlobal Nar.Base.String.fromInt
+Apply 1
+LoadConst 2
+LoadGlobal Nar.Base.String.fromInt
+Apply 1
+LoadConst 3
+LoadGlobal Nar.Base.String.fromInt
+Apply 1
+MakeObject List 3
+`)
+	r.expectBytecode(t, "tuple", `
+LoadConst 1
+LoadGlobal Nar.Base.String.fromInt
+Apply 1
+LoadConst 2
+LoadGlobal Nar.Base.String.fromInt
+Apply 1
+LoadConst 3
+LoadGlobal Nar.Base.String.fromInt
+Apply 1
+MakeObject Tuple 3
+`)
+	r.expectBytecode(t, "record", `
+LoadConst 1
+LoadGlobal Nar.Base.String.fromInt
+Apply 1
+LoadConst "c"
+LoadConst 2
+LoadGlobal Nar.Base.String.fromInt
+Apply 1
+LoadConst "a"
+LoadConst 3
+LoadGlobal Nar.Base.String.fromInt
+Apply 1
+LoadConst "b"
+MakeObject Record 3
+`)
+	r.expectBytecode(t, "constructor", `
+LoadConst 1
+LoadGlobal Nar.Base.String.fromInt
+Apply 1
+LoadConst 2
+LoadGlobal Nar.Base.String.fromInt
+Apply 1
+LoadConst 3
+LoadGlobal Nar.Base.String.fromInt
+Apply 1
+LoadGlobal Test.P
+TailApply 3
+`)
+	r.expectBytecode(t, "P", `
+MakePattern Named _p2
+Match 0
+SwapPop Pop
+MakePattern Named _p1
+Match 0
+SwapPop Pop
+MakePattern Named _p0
+Match 0
+SwapPop Pop
+LoadLocal _p0
+LoadLocal _p1
+LoadLocal _p2
+LoadConst "Test.Pair#P"
+MakeObject Option 3
+`)
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"list", `["1", "2", "3"]`},
+		{"tuple", `("1", "2", "3")`},
+		{"record", `{a = "2", b = "3", c = "1"}`},
+		{"constructor", `P("1", "2", "3")`},
+	}
+	for _, tt := range tests {
+		if got := r.run(t, tt.name); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
@@ -16,11 +16,23 @@ import (
 
 const Version uint32 = 100
 
+// Options are build options of the compiler
+type Options struct {
+	// Debug adds debug information to the binary
+	Debug bool
+	// Optimize enables constant folding, dead branch elimination and inlining
+	Optimize bool
+}
+
 func Compile(log *logger.LogWriter, lc locator.Locator, link linker.Linker, debug bool) *bytecode.Binary {
+	return CompileWithOptions(log, lc, link, Options{Debug: debug})
+}
+
+func CompileWithOptions(log *logger.LogWriter, lc locator.Locator, link linker.Linker, options Options) *bytecode.Binary {
 	parsedModules := map[ast.QualifiedIdentifier]*parsed.Module{}
 	normalizedModules := map[ast.QualifiedIdentifier]*normalized.Module{}
 	typedModules := map[ast.QualifiedIdentifier]*typed.Module{}
-	bin, _ := CompileEx(log, lc, link, debug, parsedModules, normalizedModules, typedModules)
+	bin, _ := CompileExWithOptions(log, lc, link, options, parsedModules, normalizedModules, typedModules)
 	return bin
 }
 
@@ -30,6 +42,15 @@ func CompileEx(
 	normalizedModules map[ast.QualifiedIdentifier]*normalized.Module,
 	typedModules map[ast.QualifiedIdentifier]*typed.Module,
 ) (*bytecode.Binary, []ast.QualifiedIdentifier) {
+	return CompileExWithOptions(log, lc, link, Options{Debug: debug}, parsedModules, normalizedModules, typedModules)
+}
+
+func CompileExWithOptions(
+	log *logger.LogWriter, lc locator.Locator, link linker.Linker, options Options,
+	parsedModules map[ast.QualifiedIdentifier]*parsed.Module,
+	normalizedModules map[ast.QualifiedIdentifier]*normalized.Module,
+	typedModules map[ast.QualifiedIdentifier]*typed.Module,
+) (*bytecode.Binary, []ast.QualifiedIdentifier) {
 
 	bin := bytecode.NewBinary()
 	bin.CompilerVersion = Version
@@ -42,7 +63,11 @@ func CompileEx(
 	}
 
 	for _, pkg := range packages {
-		bin.Packages[bytecode.QualifiedIdentifier(pkg.Info().Name)] = uint32(pkg.Info().Version)
+		bin.Packages[bytecode.QualifiedIdentifier(pkg.Info().Name)] = pkg.Info().Version.String()
+		//content hash is computed and cached by locator when packages are resolved
+		if contentHash, err := pkg.ContentHash(); err == nil {
+			bin.PackageHashes[bytecode.QualifiedIdentifier(pkg.Info().Name)] = contentHash
+		}
 	}
 
 	affectedModuleNames := nar_compiler.Compile(
@@ -52,6 +77,28 @@ func CompileEx(
 		normalizedModules,
 		typedModules)
 
+	if len(log.Errors()) == 0 {
+		//dependencies cannot be changed by user, so only root packages are linted
+		graph, err := lc.Graph()
+		if err != nil {
+			log.Err(err)
+			return bin, nil
+		}
+		var rootNames []ast.PackageIdentifier
+		for _, root := range graph.Roots {
+			rootNames = append(rootNames, ast.PackageIdentifier(root.Package.Info().Name))
+		}
+		nar_compiler.Lint(log, rootNames, affectedModuleNames, parsedModules, typedModules)
+	}
+
+	if len(log.Errors()) == 0 && options.Optimize {
+		for _, name := range affectedModuleNames {
+			if m, ok := typedModules[name]; ok {
+				m.Optimize()
+			}
+		}
+	}
+
 	if len(log.Errors()) == 0 {
 		for _, name := range affectedModuleNames {
 			m, ok := typedModules[name]
@@ -59,7 +106,7 @@ func CompileEx(
 				log.Err(common.NewSystemError(fmt.Errorf("module '%s' not found", name)))
 				continue
 			}
-			if err := m.Compose(typedModules, debug, bin, hash); err != nil {
+			if err := m.Compose(typedModules, options.Debug, bin, hash); err != nil {
 				log.Err(err)
 			}
 		}
@@ -67,7 +114,7 @@ func CompileEx(
 
 	if !log.Err() {
 		if link != nil {
-			err := link.Link(log, bin, lc, debug)
+			err := link.Link(log, bin, lc, options.Debug)
 			if err != nil {
 				log.Err(err)
 			}
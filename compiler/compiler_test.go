@@ -0,0 +1,90 @@
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nar-lang/nar-compiler/ast"
+	"github.com/nar-lang/nar-compiler/ast/normalized"
+	"github.com/nar-lang/nar-compiler/ast/parsed"
+	"github.com/nar-lang/nar-compiler/ast/typed"
+	"github.com/nar-lang/nar-compiler/bytecode"
+	"github.com/nar-lang/nar-compiler/locator"
+	"github.com/nar-lang/nar-compiler/logger"
+)
+
+// testResult is a compiled test program
+type testResult struct {
+	bin   *bytecode.Binary
+	log   *logger.LogWriter
+	typed map[ast.QualifiedIdentifier]*typed.Module
+}
+
+// testSources returns sources of module `Test` and the minimal base library from testdata/base
+func testSources(t *testing.T, src string) map[string][]rune {
+	t.Helper()
+	sources := map[string][]rune{"test.nar": []rune(src)}
+	files, err := filepath.Glob(filepath.Join("testdata", "base", "*.nar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources[file] = []rune(string(content))
+	}
+	return sources
+}
+
+// compileTest compiles module `Test` with the minimal base library from testdata/base
+func compileTest(t *testing.T, src string, optimize bool) testResult {
+	t.Helper()
+	lc := locator.NewLocator(locator.NewMemoryPackageProvider(
+		locator.PackageInfo{Name: "test", Version: locator.Version{Major: 1}}, testSources(t, src)))
+	log := &logger.LogWriter{}
+	typedModules := map[ast.QualifiedIdentifier]*typed.Module{}
+	bin, _ := CompileExWithOptions(log, lc, nil, Options{Debug: true, Optimize: optimize},
+		map[ast.QualifiedIdentifier]*parsed.Module{},
+		map[ast.QualifiedIdentifier]*normalized.Module{},
+		typedModules)
+	return testResult{bin: bin, log: log, typed: typedModules}
+}
+
+// mustCompile compiles test program and fails the test on any compilation error
+func mustCompile(t *testing.T, src string, optimize bool) testResult {
+	t.Helper()
+	r := compileTest(t, src, optimize)
+	for _, err := range r.log.Errors() {
+		t.Error(err)
+	}
+	if t.Failed() {
+		t.FailNow()
+	}
+	return r
+}
+
+// expectError compiles test program and checks that one of reported errors contains the message
+func expectError(t *testing.T, src string, message string) {
+	t.Helper()
+	r := compileTest(t, src, false)
+	for _, err := range r.log.Errors() {
+		if strings.Contains(err.Error(), message) {
+			return
+		}
+	}
+	t.Errorf("expected error `%s`, got %v", message, r.log.Errors())
+}
+
+// definition returns definition of module `Test`
+func (r testResult) definition(t *testing.T, name ast.Identifier) *typed.Definition {
+	t.Helper()
+	def, ok := r.typed["Test"].FindDefinition(name)
+	if !ok {
+		t.Fatalf("definition `%s` not found", name)
+	}
+	return def
+}
@@ -0,0 +1,44 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/nar-lang/nar-compiler/locator"
+	"github.com/nar-lang/nar-compiler/logger"
+)
+
+func TestModuleImportCycle(t *testing.T) {
+	sources := testSources(t, `
+module Test
+
+import Cycle.A
+
+def main = Cycle.A.a
+`)
+	sources["a.nar"] = []rune(`
+module Cycle.A
+
+import Cycle.B
+
+def a: Int = 1
+def fromB: Int = Cycle.B.b
+`)
+	sources["b.nar"] = []rune(`
+module Cycle.B
+
+import Cycle.A
+
+def b: Int = Cycle.A.a
+`)
+	lc := locator.NewLocator(locator.NewMemoryPackageProvider(
+		locator.PackageInfo{Name: "test", Version: locator.Version{Major: 1}}, sources))
+	log := &logger.LogWriter{}
+	CompileWithOptions(log, lc, nil, Options{})
+	expected := "a.nar:1:1 import cycle: Cycle.A -> Cycle.B -> Cycle.A"
+	for _, err := range log.Errors() {
+		if err.Error() == expected {
+			return
+		}
+	}
+	t.Errorf("expected error `%s`, got %v", expected, log.Errors())
+}
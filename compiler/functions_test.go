@@ -0,0 +1,69 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLocalFunctionGroups(t *testing.T) {
+	r := mustCompile(t, `
+module Test
+
+import Nar.Base.Math exposing *
+
+def parity(n: Int, even: Int, odd: Int): Int =
+  let isEven(k: Int): Int = select k case 0 -> even case _ -> isOdd(k - 1) end
+  let isOdd(k: Int): Int = select k case 0 -> odd case _ -> isEven(k - 1) end
+  in isEven(n)
+
+def mixed(x: Int, y: Int): Int =
+  let a(k: Int): Int = x + b(k)
+  let b(k: Int): Int = y * k
+  in a(2) + b(1)
+
+def pingPong(n: Int): Int =
+  let ping(k: Int): Int = select k case 0 -> 0 case _ -> pong(k - 1) end
+  let pong(k: Int): Int = select k case 0 -> 1 case _ -> ping(k - 1) end
+  in ping(n)
+
+def main = (parity(4, 1, 2), parity(3, 1, 2), mixed(10, 3), pingPong(5))
+`, false)
+
+	for _, w := range r.log.Warnings() {
+		t.Errorf("unexpected warning: %v", w)
+	}
+	if got, expected := r.run(t, "main"), "(1, 2, 19, 1)"; got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestClosureConversion(t *testing.T) {
+	r := mustCompile(t, `
+module Test
+
+import Nar.Base.Math exposing *
+import Nar.Base.List exposing *
+
+def add3(a: Int, b: Int, c: Int): Int = a + b + c
+
+def partial = add3(1, 2)
+def lambda = map(\(x: Int) -> x * 2, [1, 2])
+def capturing(k: Int): List[Int] = map(\(x: Int) -> x * k, [1, 2])
+def main = (map(partial, [3]), lambda, capturing(3))
+`, false)
+
+	r.expectBytecode(t, "partial", `
+LoadConst 1
+LoadConst 2
+MakeClosure Test.add3 2
+`)
+	if ops := r.disassemble(t, "lambda"); strings.Contains(ops, "MakeClosure") {
+		t.Errorf("lambda without captures should be loaded as a global:\n%s", ops)
+	}
+	if ops := r.disassemble(t, "capturing"); strings.Count(ops, "MakeClosure") != 1 {
+		t.Errorf("expected a single closure:\n%s", ops)
+	}
+	if got, expected := r.run(t, "main"), "([6], [2, 4], [3, 6])"; got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,98 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+)
+
+const inlinerSource = `
+module Test
+
+import Nar.Base.Math exposing *
+import Nar.Base.String exposing *
+import Nar.Base.List exposing *
+
+type Opt = Some(Int) | None
+
+def double(x: Int): Int = x * 2
+def twice(x: Int, y: Int): Int = x + x + y
+def name(p: { name: String }): String = p.name
+def wrap(x: Int): Opt = Some(x)
+def pair(x: Int): (Int, String) = (x, fromInt(x))
+def none: Opt = None
+def rec(n: Int): Int = select n case 0 -> 0 case _ -> rec(n - 1) end
+
+def g(a: Int): Int = double(a) + twice(double(a), 3)
+def h = wrap(double(4))
+def k(a: Int): Int = rec(a)
+def l(a: Int): (Int, String) = pair(twice(a, a))
+def m = name({ name = "inlined" })
+def n = (none, map(double, [1, 2, 3]))
+def main = (g(5), h, k(3), l(2), m, n)
+`
+
+func TestInlinedProgramResults(t *testing.T) {
+	plain := mustCompile(t, inlinerSource, false)
+	optimized := mustCompile(t, inlinerSource, true)
+
+	expected := `(33, Some(8), 0, (6, "6"), "inlined", (None, [2, 4, 6]))`
+	if got := plain.run(t, "main"); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+	if got := optimized.run(t, "main"); got != expected {
+		t.Errorf("optimized: expected %s, got %s", expected, got)
+	}
+
+	for _, name := range []string{"g", "h", "l", "m"} {
+		ops := optimized.disassemble(t, name)
+		for _, inlined := range []string{"Test.double", "Test.twice", "Test.wrap", "Test.pair", "Test.name"} {
+			if strings.Contains(ops, inlined) {
+				t.Errorf("%s should be inlined into %s:\n%s", inlined, name, ops)
+			}
+		}
+	}
+	if ops := optimized.disassemble(t, "k"); !strings.Contains(ops, "Test.rec") {
+		t.Errorf("recursive definition should not be inlined:\n%s", ops)
+	}
+}
+
+func TestInlinedArgumentsEvaluation(t *testing.T) {
+	src := `
+module Test
+
+import Nar.Base.String exposing *
+
+def ign(x: Int, y: String): Int = x
+def ord(x: String, y: String): String = y ++ x
+def unused: Int = ign(1, fromInt(2))
+def ordered: String = ord(fromInt(1), fromInt(2))
+def main = (unused, ordered)
+`
+	r := mustCompile(t, src, true)
+	if got := r.run(t, "main"); got != `(1, "21")` {
+		t.Errorf("expected (1, \"21\"), got %s", got)
+	}
+	r.expectBytecode(t, "unused", `
+LoadConst 2
+Call Nar.Base.String.fromInt 1
+MakePattern Named _inl_Test_1
+Match 0
+SwapPop Pop
+LoadConst 1
+`)
+	r.expectBytecode(t, "ordered", `
+LoadConst 1
+Call Nar.Base.String.fromInt 1
+MakePattern Named _inl_Test_2
+Match 0
+SwapPop Pop
+LoadConst 2
+Call Nar.Base.String.fromInt 1
+MakePattern Named _inl_Test_3
+Match 0
+SwapPop Pop
+LoadLocal _inl_Test_3
+LoadLocal _inl_Test_2
+Call Nar.Base.String.append 2
+`)
+}
@@ -0,0 +1,50 @@
+package compiler
+
+import (
+	"testing"
+)
+
+func TestStringInterpolation(t *testing.T) {
+	r := mustCompile(t, `
+module Test
+
+import Nar.Base.Math exposing *
+import Nar.Base.String exposing *
+
+type Color = Red | Green
+
+def name = "Nar"
+def age: Int = 3
+def plain = "Hello ${name}, you are ${fromInt(age)}"
+def shown = "${age} + ${1.5} = ${Green}, ${[1, 2]}!"
+def escaped = "cost: \${age}"
+def multiline =
+  """
+    Hello ${name},
+      age ${age}
+    bye"""
+def rawMultiline = r"""${name}"""
+`, false)
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"plain", `"Hello Nar, you are 3"`},
+		{"shown", `"3 + 1.5 = Green, [1, 2]!"`},
+		{"escaped", `"cost: ${age}"`},
+		{"multiline", `"Hello Nar,\n  age 3\nbye"`},
+		{"rawMultiline", `"${name}"`},
+	}
+	for _, tt := range tests {
+		if got := r.run(t, tt.name); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestStringInterpolationErrors(t *testing.T) {
+	expectError(t, "module Test\n\ndef a = \"x ${}\"", "expected interpolated expression here")
+	expectError(t, "module Test\n\ndef a = \"\"\"x ${1 ]\"\"\"", "expected `}` here")
+	expectError(t, "module Test\n\ndef a = \"\"\"x ${1}\n", "multiline string is not closed before the end of file")
+}
@@ -0,0 +1,97 @@
+package compiler
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/nar-lang/nar-compiler/locator"
+	"github.com/nar-lang/nar-compiler/logger"
+)
+
+// dependencyProvider provides package that can be loaded as dependency but is not exported
+type dependencyProvider struct {
+	pkg locator.Package
+}
+
+func (p dependencyProvider) ExportedPackages() ([]locator.Package, error) {
+	return nil, nil
+}
+
+func (p dependencyProvider) LoadPackage(name string) (locator.Package, bool, error) {
+	return p.pkg, p.pkg.Info().Name == name, nil
+}
+
+func TestLintRootPackagesOnly(t *testing.T) {
+	base := map[string][]rune{}
+	files, err := filepath.Glob(filepath.Join("testdata", "base", "*.nar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		base[file] = []rune(string(content))
+	}
+	base["lib.nar"] = []rune(`
+module Lib
+
+import Nar.Base.Math exposing *
+
+def unusedParam(x: Int, y: Int): Int = x
+`)
+	var sources []locator.ModuleSource
+	for path, content := range base {
+		sources = append(sources, locator.NewModuleSource(path, content))
+	}
+	lib := locator.NewLoadedPackage(locator.PackageInfo{Name: "lib", Version: locator.Version{Major: 1}}, sources, "")
+
+	constraint, err := locator.ParseConstraint("^1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := locator.NewMemoryPackageProvider(
+		locator.PackageInfo{
+			Name:         "test",
+			Version:      locator.Version{Major: 1},
+			Dependencies: map[string]locator.Constraint{"lib": constraint},
+		},
+		map[string][]rune{"test.nar": []rune(`
+module Test
+
+import Lib
+import Nar.Base.Math exposing *
+
+def f(a: Int, b: Int): Int =
+  let unused(k: Int): Int = k + a
+  let ping(k: Int): Int = pong(k)
+  let pong(k: Int): Int = ping(k)
+  let used(k: Int): Int = k * 2
+  in used(a) + Lib.unusedParam(1, 2)
+`)})
+
+	log := &logger.LogWriter{}
+	CompileWithOptions(log, locator.NewLocator(root, dependencyProvider{pkg: lib}), nil, Options{Debug: true})
+	for _, err := range log.Errors() {
+		t.Fatal(err)
+	}
+
+	var warnings []string
+	for _, w := range log.Warnings() {
+		warnings = append(warnings, w.Error())
+	}
+	expected := []string{
+		"test.nar:10:7 local function `pong` is not used",
+		"test.nar:7:15 parameter `b` is not used",
+		"test.nar:8:7 local function `unused` is not used",
+		"test.nar:9:7 local function `ping` is not used",
+	}
+	slices.Sort(warnings)
+	if fmt.Sprint(warnings) != fmt.Sprint(expected) {
+		t.Errorf("expected warnings:\n%v\ngot:\n%v", expected, warnings)
+	}
+}
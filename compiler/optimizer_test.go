@@ -0,0 +1,84 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+)
+
+const optimizerSource = `
+module Test
+
+import Nar.Base.Basics exposing *
+import Nar.Base.Math exposing *
+import Nar.Base.String exposing *
+
+type Opt = Some(Int) | None
+
+def ints: Int = 1 + 2 * 3 - neg(4)
+def floats: Float = 1.5 * 2.0 - neg(1.0)
+def floatLiterals: Float = 1 + 2 * 3
+def overflow: Int = 9223372036854775807 + 1
+def mulOverflow: Int = 4611686018427387904 * 2
+def constSelect = select True case False -> 1 case True -> 2 end
+def intSelect(x: Int): Int = select 3 case 1 -> x case 3 -> x + 1 case _ -> 0 end
+def trueIf(x: Int): Int = if True then x else x * 2
+def unusedPure(x: Int): Int = let y = x in let z = \(a: Int) -> a + x in x * 2
+def unusedCall(x: Int): Int = let s = fromInt(x) in x * 2
+def optionSelect(a: Int): Opt = select None case None -> Some(a) case Some(_) -> None end
+def anySelect(x: Int): Int = select fromInt(x) case _ -> x end
+def pureAnySelect(x: Int): Int = select x case _ -> x + 1 end
+def main = (
+  (ints, floats, floatLiterals, overflow, mulOverflow),
+  (constSelect, intSelect(5), trueIf(6), unusedPure(7), unusedCall(8)),
+  optionSelect(9),
+  (anySelect(10), pureAnySelect(11)))
+`
+
+func TestOptimizedProgramResults(t *testing.T) {
+	plain := mustCompile(t, optimizerSource, false)
+	optimized := mustCompile(t, optimizerSource, true)
+
+	expected := "((11, 4, 7, -9223372036854775808, -9223372036854775808), (2, 6, 6, 14, 16), Some(9), (10, 12))"
+	if got := plain.run(t, "main"); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+	if got := optimized.run(t, "main"); got != expected {
+		t.Errorf("optimized: expected %s, got %s", expected, got)
+	}
+}
+
+func TestConstantFolding(t *testing.T) {
+	r := mustCompile(t, optimizerSource, true)
+	r.expectBytecode(t, "ints", `
+LoadConst 11
+`)
+	r.expectBytecode(t, "floats", `
+LoadConst 4
+`)
+	r.expectBytecode(t, "floatLiterals", `
+LoadConst 7
+`)
+	if ops := r.disassemble(t, "overflow"); !strings.Contains(ops, "Nar.Base.Math.add") {
+		t.Errorf("overflowing addition should not be folded:\n%s", ops)
+	}
+	if ops := r.disassemble(t, "mulOverflow"); !strings.Contains(ops, "Nar.Base.Math.mul") {
+		t.Errorf("overflowing multiplication should not be folded:\n%s", ops)
+	}
+	if ops := r.disassemble(t, "unusedCall"); !strings.Contains(ops, "Nar.Base.String.fromInt") {
+		t.Errorf("unused let with a call should be kept:\n%s", ops)
+	}
+	if ops := r.disassemble(t, "anySelect"); !strings.Contains(ops, "Nar.Base.String.fromInt") {
+		t.Errorf("select with a call in condition should be kept:\n%s", ops)
+	}
+	r.expectBytecode(t, "pureAnySelect", `
+MakePattern Named x
+Match 0
+SwapPop Pop
+LoadLocal x
+LoadConst 1
+Call Nar.Base.Math.add 2
+`)
+	if ops := r.disassemble(t, "unusedPure"); strings.Contains(ops, "Named y") || strings.Contains(ops, "Named z") {
+		t.Errorf("unused pure lets should be removed:\n%s", ops)
+	}
+}
@@ -0,0 +1,57 @@
+package compiler
+
+import (
+	"github.com/nar-lang/nar-compiler/ast"
+	"strings"
+	"testing"
+)
+
+const recordsSource = `
+module Test
+
+import Nar.Base.Math exposing *
+import Nar.Base.String exposing *
+
+def getName(p: { r | name: String }): String = p.name
+def rename(p: { r | name: String }, n: String): { r | name: String } = { p | name = n }
+def inferred(p) = p.name
+def both(p) = p.name ++ fromInt(p.age)
+def closed(p: { name: String }): String = p.name
+`
+
+func TestExtensibleRecordUnification(t *testing.T) {
+	r := mustCompile(t, recordsSource+`
+def a = getName({ name = "x", age = 1 })
+def b = rename({ name = "z", age = 'y' }, "w").age
+def c = inferred({ name = 'x', k = 2 })
+def d = both({ name = "n", age = 3, tall = 4 })
+`, false)
+
+	tests := []struct {
+		name     ast.Identifier
+		expected string
+	}{
+		{"a", "Nar.Base.String.String"},
+		{"b", "Nar.Base.Char.Char"},
+		{"c", "Nar.Base.Char.Char"},
+		{"d", "Nar.Base.String.String"},
+	}
+	for _, tt := range tests {
+		if got := r.definition(t, tt.name).Body().Type().Code("Test"); got != tt.expected {
+			t.Errorf("type of %s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+
+	param := r.definition(t, "both").Params()[0].Type().Code("Test")
+	for _, field := range []string{"name:", "age:", " | "} {
+		if !strings.Contains(param, field) {
+			t.Errorf("inferred parameter type %s should be extensible record with `%s`", param, field)
+		}
+	}
+}
+
+func TestExtensibleRecordErrors(t *testing.T) {
+	expectError(t, recordsSource+`def a = getName({ age = 1 })`, "record missing field `name`")
+	expectError(t, recordsSource+`def a = closed({ name = "x", age = 1 })`, "record missing field `age`")
+	expectError(t, recordsSource+`def a = rename({ name = 'x' }, "y")`, "cannot match Nar.Base.String.String and Nar.Base.Char.Char")
+}
@@ -0,0 +1,93 @@
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nar-lang/nar-compiler/ast"
+	"github.com/nar-lang/nar-compiler/ast/normalized"
+	"github.com/nar-lang/nar-compiler/ast/parsed"
+	"github.com/nar-lang/nar-compiler/ast/typed"
+	"github.com/nar-lang/nar-compiler/locator"
+	"github.com/nar-lang/nar-compiler/logger"
+)
+
+func TestRecompileReloadedModule(t *testing.T) {
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "src")
+	if err := os.MkdirAll(srcDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name string, content string) {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("nar.json", `{"name": "test", "version": "1.0.0"}`)
+	files, err := filepath.Glob(filepath.Join("testdata", "base", "*.nar"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		write(filepath.Join("src", filepath.Base(file)), string(content))
+	}
+	write("src/Test.nar", `
+module Test
+
+import Lib
+import Nar.Base.Math exposing *
+
+def main: Int = Lib.value + 1
+`)
+	write("src/Lib.nar", `
+module Lib
+
+def value: Int = 1
+`)
+
+	lc := locator.NewLocator(locator.NewFileSystemPackageProvider(root))
+	parsedModules := map[ast.QualifiedIdentifier]*parsed.Module{}
+	normalizedModules := map[ast.QualifiedIdentifier]*normalized.Module{}
+	typedModules := map[ast.QualifiedIdentifier]*typed.Module{}
+	compile := func() testResult {
+		t.Helper()
+		log := &logger.LogWriter{}
+		bin, _ := CompileExWithOptions(log, lc, nil, Options{Debug: true},
+			parsedModules, normalizedModules, typedModules)
+		for _, err := range log.Errors() {
+			t.Fatal(err)
+		}
+		return testResult{bin: bin, log: log, typed: typedModules}
+	}
+
+	if result := compile().run(t, "main"); result != "2" {
+		t.Errorf("expected 2, got %s", result)
+	}
+	test, math := typedModules["Test"], typedModules["Nar.Base.Math"]
+
+	write("src/Lib.nar", `
+module Lib
+
+def value: Int = 10
+`)
+	modTime := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(srcDir, "Lib.nar"), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	if result := compile().run(t, "main"); result != "11" {
+		t.Errorf("expected 11 after reload, got %s", result)
+	}
+	if typedModules["Test"] == test {
+		t.Errorf("dependent module `Test` is not recompiled")
+	}
+	if typedModules["Nar.Base.Math"] != math {
+		t.Errorf("unchanged module `Nar.Base.Math` is recompiled")
+	}
+}
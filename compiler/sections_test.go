@@ -0,0 +1,27 @@
+package compiler
+
+import "testing"
+
+func TestOperatorSections(t *testing.T) {
+	src := `
+module Test
+
+import Nar.Base.Basics exposing *
+import Nar.Base.Math exposing *
+import Nar.Base.List exposing *
+
+def max(a: Int, b: Int): Int = if a < b then b else a
+def sub(a: Int, b: Int): Int = a - b
+
+def sections = (map((+ 1), [1, 2]), (10 -)(3), (|max| 2)(1), (|max| 2)(5), (|sub| 1)(10), (10 |sub|)(3))
+def named = (4 |max| 7, 1 + 5 |max| 3 * 2, 10 |sub| 3 |sub| 2)
+def main = (sections, named)
+`
+	for _, optimize := range []bool{false, true} {
+		r := mustCompile(t, src, optimize)
+		expected := "(([2, 3], 7, 2, 5, 9, 7), (7, 11, 5))"
+		if got := r.run(t, "main"); got != expected {
+			t.Errorf("optimize=%v: expected %s, got %s", optimize, expected, got)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNestedSelectSwitch(t *testing.T) {
+	r := mustCompile(t, `
+module Test
+
+type Color = Red | Green | Blue
+
+def f(a: Color, b: Color): String =
+  select a
+    case Red ->
+      select b
+        case Green -> "red green"
+        case Blue -> "red blue"
+        case _ -> "red other"
+      end
+    case Green -> "green"
+    case _ -> "other"
+  end
+
+def main = (f(Red, Green), f(Red, Blue), f(Red, Red), f(Green, Red), f(Blue, Red))
+`, false)
+
+	if ops := r.disassemble(t, "f"); strings.Count(ops, "Switch") != 2 {
+		t.Errorf("expected outer and nested switch:\n%s", ops)
+	}
+	expected := `("red green", "red blue", "red other", "green", "other")`
+	if got := r.run(t, "main"); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestSelectDecisionTree(t *testing.T) {
+	r := mustCompile(t, `
+module Test
+
+type Color = Red | Green | Blue
+type Opt = Some(Color) | None
+type Shape = Circle(Color, Opt) | Square(Opt)
+
+def name(c: Color): String =
+  select c
+    case Red -> "red"
+    case Green -> "green"
+    case Blue -> "blue"
+  end
+
+def describe(s: Shape): String =
+  select s
+    case Circle(Red, Some(c)) -> "red circle with " ++ name(c)
+    case Circle(c, None) -> name(c) ++ " circle"
+    case Square(Some(Blue) as o) -> "blue square"
+    case Circle(_, o) -> "circle"
+    case Square(_) -> "square"
+  end
+
+def pair(a: Opt, b: Opt): String =
+  select (a, b)
+    case (Some(x), Some(y)) -> name(x) ++ name(y)
+    case (None, Some(y)) -> name(y)
+    case (x, None) -> "none"
+  end
+
+def alias(x: Opt): Opt =
+  select x
+    case Some(Red) as o -> o
+    case _ -> None
+  end
+
+def main = (
+  describe(Circle(Red, Some(Blue))),
+  describe(Circle(Green, None)),
+  describe(Circle(Green, Some(Red))),
+  describe(Square(Some(Blue))),
+  describe(Square(Some(Red))),
+  describe(Square(None)),
+  (pair(Some(Red), Some(Green)), pair(None, Some(Blue)), pair(Some(Red), None)),
+  (alias(Some(Red)), alias(Some(Blue))))
+`, false)
+
+	expected := `("red circle with blue", "green circle", "circle", "blue square", "square", "square", ` +
+		`("redgreen", "blue", "none"), (Some(Red), None))`
+	if got := r.run(t, "main"); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+
+	//constructor tags are dispatched with switches, so pattern matches only extract arguments and never fail
+	ops := r.disassemble(t, "describe")
+	if n := strings.Count(ops, "Switch"); n != 6 {
+		t.Errorf("expected 6 switches, got %d:\n%s", n, ops)
+	}
+	for _, line := range strings.Split(ops, "\n") {
+		if strings.HasPrefix(line, "Match") && line != "Match 0" {
+			t.Errorf("unexpected conditional match `%s`:\n%s", line, ops)
+		}
+	}
+}
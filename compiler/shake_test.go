@@ -0,0 +1,107 @@
+package compiler
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/nar-lang/nar-compiler/bytecode"
+	"github.com/nar-lang/nar-compiler/linker"
+	"github.com/nar-lang/nar-compiler/locator"
+	"github.com/nar-lang/nar-compiler/logger"
+)
+
+const shakeSource = `
+module Test
+
+import Nar.Base.Basics exposing *
+import Nar.Base.Math exposing *
+import Nar.Base.List exposing *
+
+type Color = Red | Green | Blue
+
+def unusedSelect(c: Color): String = select c case Red -> "unused red" case _ -> "unused other" end
+def unusedConst: Int = 123456789
+def unusedCaller: String = unusedSelect(Red)
+
+def name(c: Color): String = select c case Red -> "red" case Green -> "green" case _ -> "blue" end
+def add(x: Int, y: Int): Int = x + y
+def exported: Float = 2.5
+def main = (map(name, [Red, Green, Blue]), map(add(40), [2]), 987654321)
+`
+
+// nopLinker is the last linker in chain that leaves binary as is
+type nopLinker struct{}
+
+func (nopLinker) Link(*logger.LogWriter, *bytecode.Binary, locator.Locator, bool) error {
+	return nil
+}
+
+func TestShake(t *testing.T) {
+	r := mustCompile(t, shakeSource, false)
+	expected := `(["red", "green", "blue"], [42], 987654321)`
+	if got := r.run(t, "main"); got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+	numFuncs, numConsts := len(r.bin.Funcs), len(r.bin.Consts)
+	if len(r.bin.JumpTables) < 2 || !slices.Contains(r.bin.Strings, "unused red") {
+		t.Fatalf("unused function should have jump table and strings before shaking")
+	}
+
+	if err := r.bin.Shake([]bytecode.FullIdentifier{"Test.main", "Test.exported"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.run(t, "main"); got != expected {
+		t.Errorf("shaken: expected %s, got %s", expected, got)
+	}
+	if got := r.run(t, "exported"); got != "2.5" {
+		t.Errorf("shaken: expected 2.5, got %s", got)
+	}
+	for _, removed := range []bytecode.FullIdentifier{"Test.unusedSelect", "Test.unusedConst", "Test.unusedCaller"} {
+		if _, ok := r.bin.Exports[removed]; ok {
+			t.Errorf("%s should be removed", removed)
+		}
+	}
+	for _, kept := range []bytecode.FullIdentifier{"Test.name", "Test.add", "Nar.Base.List.map"} {
+		if _, ok := r.bin.Exports[kept]; !ok {
+			t.Errorf("%s should be kept", kept)
+		}
+	}
+	if len(r.bin.Funcs) >= numFuncs || len(r.bin.Consts) >= numConsts {
+		t.Errorf("functions and consts should be removed: %d -> %d, %d -> %d",
+			numFuncs, len(r.bin.Funcs), numConsts, len(r.bin.Consts))
+	}
+	if len(r.bin.JumpTables) != 1 {
+		t.Errorf("expected 1 jump table, got %d", len(r.bin.JumpTables))
+	}
+	if slices.Contains(r.bin.Strings, "unused red") || r.bin.Strings[0] != "" {
+		t.Errorf("unexpected strings %q", r.bin.Strings)
+	}
+
+	if err := r.bin.Shake([]bytecode.FullIdentifier{"Test.unusedConst"}); err == nil {
+		t.Errorf("expected error for removed root")
+	}
+}
+
+func TestShakingLinker(t *testing.T) {
+	lc := locator.NewLocator(locator.NewMemoryPackageProvider(
+		locator.PackageInfo{Name: "test", Version: locator.Version{Major: 1}, Main: "Test.main"},
+		testSources(t, shakeSource)))
+	log := &logger.LogWriter{}
+	bin := CompileWithOptions(log, lc, linker.NewShakingLinker(nopLinker{}, "Test.exported"), Options{})
+	for _, err := range log.Errors() {
+		t.Fatal(err)
+	}
+	if bin.Entry != "Test.main" {
+		t.Errorf("expected entry Test.main, got %s", bin.Entry)
+	}
+	r := testResult{bin: bin, log: log}
+	if got := r.run(t, "main"); got != `(["red", "green", "blue"], [42], 987654321)` {
+		t.Errorf("unexpected result %s", got)
+	}
+	if got := r.run(t, "exported"); got != "2.5" {
+		t.Errorf("expected 2.5, got %s", got)
+	}
+	if _, ok := bin.Exports["Test.unusedCaller"]; ok {
+		t.Errorf("Test.unusedCaller should be removed")
+	}
+}
@@ -0,0 +1,59 @@
+package compiler
+
+import (
+	"testing"
+)
+
+func TestStringLiterals(t *testing.T) {
+	r := mustCompile(t, `
+module Test
+
+def raw = r"C:\new\table\"
+def rawDollar = r"${name}"
+def escapes = "tab\tquote\"dollar\$ backslash\\"
+def interpolated = "${raw}\\"
+def oneLine = """hi"""
+def blankEdges = """
+
+    a
+    b
+
+"""
+def blankInside = """
+    a
+
+    b
+"""
+def mixedIndent = """
+`+"\t  a\n    b\n"+`"""
+def escapedMultiline = """
+  a\tb
+  \"q\"
+"""
+def rawMultiline = r"""
+  C:\new
+    \t
+"""
+`, false)
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"raw", `"C:\\new\\table\\"`},
+		{"rawDollar", `"${name}"`},
+		{"escapes", `"tab\tquote\"dollar$ backslash\\"`},
+		{"interpolated", `"C:\\new\\table\\\\"`},
+		{"oneLine", `"hi"`},
+		{"blankEdges", `"\na\nb\n"`},
+		{"blankInside", `"a\n\nb"`},
+		{"mixedIndent", `"a\n b"`},
+		{"escapedMultiline", `"a\tb\n\"q\""`},
+		{"rawMultiline", `"C:\\new\n  \\t"`},
+	}
+	for _, tt := range tests {
+		if got := r.run(t, tt.name); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
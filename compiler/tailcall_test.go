@@ -0,0 +1,96 @@
+package compiler
+
+import (
+	"strings"
+	"testing"
+)
+
+const tailCallSource = `
+module Test
+
+import Nar.Base.Basics exposing *
+import Nar.Base.Math exposing *
+
+def loop(n: Int, acc: Int): Int = if n == 0 then acc else loop(n - 1, acc + n)
+def sum(n: Int): Int = if n == 0 then 0 else n + sum(n - 1)
+def isEven(n: Int): Bool = if n == 0 then True else isOdd(n - 1)
+def isOdd(n: Int): Bool = if n == 0 then False else isEven(n - 1)
+def countdown(n: Int): Int = select n case 0 -> 0 case _ -> let m = n - 1 in countdown(m) end
+
+def deepLoop = loop(100000, 0)
+def deepCountdown = countdown(100000)
+def shallowSum = sum(1000)
+def parity = (isEven(101), isOdd(101))
+`
+
+func TestTailCallBytecode(t *testing.T) {
+	r := mustCompile(t, tailCallSource, false)
+	r.expectBytecode(t, "loop", `
+MakePattern Named acc
+Match 0
+SwapPop Pop
+MakePattern Named n
+Match 0
+SwapPop Pop
+LoadLocal n
+LoadConst 0
+LoadGlobal Nar.Base.Math.eq
+Apply 2
+Switch Nar.Base.Basics.Bool#True:0 Nar.Base.Basics.Bool#False:2 _:0
+LoadLocal acc
+Jump 10
+LoadLocal n
+LoadConst 1
+LoadGlobal Nar.Base.Math.sub
+Apply 2
+LoadLocal acc
+LoadLocal n
+LoadGlobal Nar.Base.Math.add
+Apply 2
+TailJump 2 -22
+Jump 0
+SwapPop Both
+`)
+
+	ops := r.disassemble(t, "sum")
+	if !strings.Contains(ops, "LoadGlobal Test.sum\nApply 1\n") || strings.Contains(ops, "TailJump") {
+		t.Errorf("recursive call that is not in tail position should be a plain apply:\n%s", ops)
+	}
+	if !strings.Contains(ops, "LoadGlobal Nar.Base.Math.add\nTailApply 2\n") {
+		t.Errorf("call in tail position should be a tail apply:\n%s", ops)
+	}
+	if ops := r.disassemble(t, "isEven"); !strings.Contains(ops, "LoadGlobal Test.isOdd\nTailApply 1\n") {
+		t.Errorf("call of other function in tail position should be a tail apply:\n%s", ops)
+	}
+	if ops := r.disassemble(t, "countdown"); !strings.Contains(ops, "TailJump 1 -20\n") {
+		t.Errorf("self call in tail position of let and select should be a tail jump:\n%s", ops)
+	}
+}
+
+func TestTailCallsRunInConstantStack(t *testing.T) {
+	for _, optimize := range []bool{false, true} {
+		r := mustCompile(t, tailCallSource, optimize)
+		tests := []struct {
+			name     string
+			expected string
+			maxDepth int
+		}{
+			{"deepLoop", "5000050000", 4},
+			{"deepCountdown", "0", 4},
+			{"shallowSum", "500500", 0},
+			{"parity", "(False, True)", 0},
+		}
+		for _, tt := range tests {
+			result, depth := r.runDepth(t, tt.name)
+			if result != tt.expected {
+				t.Errorf("optimize=%v: %s: expected %s, got %s", optimize, tt.name, tt.expected, result)
+			}
+			if tt.maxDepth > 0 && depth > tt.maxDepth {
+				t.Errorf("optimize=%v: %s: expected call depth at most %d, got %d", optimize, tt.name, tt.maxDepth, depth)
+			}
+		}
+		if _, depth := r.runDepth(t, "shallowSum"); depth < 1000 {
+			t.Errorf("optimize=%v: non-tail recursion should use call depth of at least 1000, got %d", optimize, depth)
+		}
+	}
+}
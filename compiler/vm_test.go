@@ -0,0 +1,405 @@
+package compiler
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/nar-lang/nar-compiler/bytecode"
+)
+
+// testVM is a minimal interpreter of the binary that is used to check results of compiled programs.
+// It implements natives of the base library from testdata/base.
+type testVM struct {
+	bin      *bytecode.Binary
+	depth    int
+	maxDepth int
+}
+
+type vmUnit struct{}
+
+type vmTuple []any
+
+type vmRecord map[string]any
+
+type vmOption struct {
+	name string
+	args []any
+}
+
+type vmClosure struct {
+	ptr  bytecode.Pointer
+	args []any
+}
+
+type vmPattern struct {
+	kind   bytecode.PatternKind
+	name   string
+	nested []any
+}
+
+type vmTrap string
+
+var vmTrue = vmOption{name: "Nar.Base.Basics.Bool#True"}
+var vmFalse = vmOption{name: "Nar.Base.Basics.Bool#False"}
+
+// run executes exported definition of module `Test` and returns formatted result
+func (r testResult) run(t *testing.T, name string, args ...any) string {
+	t.Helper()
+	result, _ := r.runDepth(t, name, args...)
+	return result
+}
+
+// runDepth executes exported definition of module `Test` and returns formatted result
+// and maximal depth of nested function calls
+func (r testResult) runDepth(t *testing.T, name string, args ...any) (result string, depth int) {
+	t.Helper()
+	ptr, ok := r.bin.Exports[bytecode.FullIdentifier("Test."+name)]
+	if !ok {
+		t.Fatalf("definition `Test.%s` is not exported", name)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("Test.%s: %v", name, r)
+			result = ""
+		}
+	}()
+	vm := &testVM{bin: r.bin}
+	result = vmFormat(vm.call(ptr, args))
+	return result, vm.maxDepth
+}
+
+func (vm *testVM) call(ptr bytecode.Pointer, args []any) any {
+	vm.depth++
+	vm.maxDepth = max(vm.maxDepth, vm.depth)
+	defer func() { vm.depth-- }()
+	fn := vm.bin.Funcs[ptr]
+	stack := slices.Clone(args)
+	locals := map[string]any{}
+	pop := func() any {
+		if len(stack) == 0 {
+			panic(vmTrap("stack underflow"))
+		}
+		x := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return x
+	}
+	popN := func(n int) []any {
+		if len(stack) < n {
+			panic(vmTrap("stack underflow"))
+		}
+		xs := slices.Clone(stack[len(stack)-n:])
+		stack = stack[:len(stack)-n]
+		return xs
+	}
+
+	for pc := 0; pc < len(fn.Ops); pc++ {
+		kind, b, c, a := fn.Ops[pc].Decompose()
+		switch kind {
+		case bytecode.OpKindLoadLocal:
+			x, ok := locals[vm.bin.Strings[a]]
+			if !ok {
+				panic(vmTrap(fmt.Sprintf("local `%s` is not defined", vm.bin.Strings[a])))
+			}
+			stack = append(stack, x)
+		case bytecode.OpKindLoadGlobal:
+			if vm.bin.Funcs[a].NumArgs == 0 {
+				stack = append(stack, vm.call(bytecode.Pointer(a), nil))
+			} else {
+				stack = append(stack, vmClosure{ptr: bytecode.Pointer(a)})
+			}
+		case bytecode.OpKindLoadConst:
+			switch bytecode.ConstKind(c) {
+			case bytecode.ConstKindUnit:
+				stack = append(stack, vmUnit{})
+			case bytecode.ConstKindChar:
+				stack = append(stack, rune(a))
+			case bytecode.ConstKindInt:
+				stack = append(stack, vm.bin.Consts[a].Int())
+			case bytecode.ConstKindFloat:
+				stack = append(stack, vm.bin.Consts[a].Float())
+			case bytecode.ConstKindString:
+				stack = append(stack, vm.bin.Strings[a])
+			}
+		case bytecode.OpKindApply:
+			f := pop()
+			stack = append(stack, vm.apply(f, popN(int(b))))
+		case bytecode.OpKindCall:
+			stack = append(stack, vm.native(vm.bin.Strings[a], popN(int(b))))
+		case bytecode.OpKindJump:
+			if b == 0 {
+				pc += int(int32(a))
+				break
+			}
+			p := pop()
+			bound := map[string]any{}
+			if !vm.match(p.(vmPattern), stack[len(stack)-1], bound) {
+				pc += int(int32(a))
+				break
+			}
+			for n, x := range bound {
+				locals[n] = x
+			}
+		case bytecode.OpKindMakeObject:
+			switch bytecode.ObjectKind(b) {
+			case bytecode.ObjectKindList:
+				stack = append(stack, popN(int(a)))
+			case bytecode.ObjectKindTuple:
+				stack = append(stack, vmTuple(popN(int(a))))
+			case bytecode.ObjectKindRecord:
+				rec := vmRecord{}
+				for i := 0; i < int(a); i++ {
+					name := pop().(string)
+					rec[name] = pop()
+				}
+				stack = append(stack, rec)
+			case bytecode.ObjectKindOption:
+				name := pop().(string)
+				stack = append(stack, vmOption{name: name, args: popN(int(a))})
+			}
+		case bytecode.OpKindMakePattern:
+			p := vmPattern{kind: bytecode.PatternKind(b)}
+			switch p.kind {
+			case bytecode.PatternKindAlias:
+				p.name = vm.bin.Strings[a]
+				p.nested = popN(1)
+			case bytecode.PatternKindNamed:
+				p.name = vm.bin.Strings[a]
+			case bytecode.PatternKindDataOption:
+				p.name = vm.bin.Strings[a]
+				p.nested = popN(int(c))
+			case bytecode.PatternKindConst:
+				p.nested = popN(1)
+			case bytecode.PatternKindCons:
+				p.nested = popN(2)
+			case bytecode.PatternKindTuple:
+				p.nested = popN(int(c))
+			case bytecode.PatternKindList, bytecode.PatternKindRecord:
+				p.nested = popN(int(a))
+			}
+			stack = append(stack, p)
+		case bytecode.OpKindAccess:
+			stack = append(stack, pop().(vmRecord)[vm.bin.Strings[a]])
+		case bytecode.OpKindUpdate:
+			x := pop()
+			rec := vmRecord{}
+			for n, f := range pop().(vmRecord) {
+				rec[n] = f
+			}
+			rec[vm.bin.Strings[a]] = x
+			stack = append(stack, rec)
+		case bytecode.OpKindSwapPop:
+			if bytecode.SwapPopMode(b) == bytecode.SwapPopModeBoth {
+				top := pop()
+				pop()
+				stack = append(stack, top)
+			} else {
+				pop()
+			}
+		case bytecode.OpKindTailApply:
+			if bytecode.TailApplyMode(c) == bytecode.TailApplyModeJump {
+				stack = popN(int(b))
+				locals = map[string]any{}
+				pc += int(int32(a))
+				break
+			}
+			f := pop()
+			return vm.apply(f, popN(int(b)))
+		case bytecode.OpKindSwitch:
+			option := stack[len(stack)-1].(vmOption)
+			table := vm.bin.JumpTables[a]
+			delta := table.Default
+			for _, cs := range table.Cases {
+				if vm.bin.Strings[cs.Option] == option.name {
+					delta = cs.Delta
+				}
+			}
+			pc += int(delta)
+		case bytecode.OpKindMakeClosure:
+			stack = append(stack, vmClosure{ptr: bytecode.Pointer(a), args: popN(int(b))})
+		default:
+			panic(vmTrap(fmt.Sprintf("unknown op kind %d", kind)))
+		}
+	}
+	if len(stack) != 1 {
+		panic(vmTrap(fmt.Sprintf("function returned with %d objects on the stack", len(stack))))
+	}
+	return stack[0]
+}
+
+func (vm *testVM) apply(f any, args []any) any {
+	closure, ok := f.(vmClosure)
+	if !ok {
+		panic(vmTrap(fmt.Sprintf("cannot apply %s", vmFormat(f))))
+	}
+	args = append(slices.Clone(closure.args), args...)
+	numArgs := int(vm.bin.Funcs[closure.ptr].NumArgs)
+	if len(args) < numArgs {
+		return vmClosure{ptr: closure.ptr, args: args}
+	}
+	result := vm.call(closure.ptr, args[:numArgs])
+	if len(args) > numArgs {
+		return vm.apply(result, args[numArgs:])
+	}
+	return result
+}
+
+func (vm *testVM) match(p vmPattern, x any, locals map[string]any) bool {
+	switch p.kind {
+	case bytecode.PatternKindAlias:
+		locals[p.name] = x
+		return vm.match(p.nested[0].(vmPattern), x, locals)
+	case bytecode.PatternKindAny:
+		return true
+	case bytecode.PatternKindCons:
+		list := x.([]any)
+		return len(list) > 0 &&
+			vm.match(p.nested[1].(vmPattern), list[0], locals) &&
+			vm.match(p.nested[0].(vmPattern), list[1:], locals)
+	case bytecode.PatternKindConst:
+		return reflect.DeepEqual(p.nested[0], x)
+	case bytecode.PatternKindDataOption:
+		option := x.(vmOption)
+		if option.name != p.name || len(option.args) != len(p.nested) {
+			return false
+		}
+		for i, arg := range option.args {
+			if !vm.match(p.nested[i].(vmPattern), arg, locals) {
+				return false
+			}
+		}
+		return true
+	case bytecode.PatternKindList:
+		list := x.([]any)
+		if len(list) != len(p.nested) {
+			return false
+		}
+		for i, item := range list {
+			if !vm.match(p.nested[i].(vmPattern), item, locals) {
+				return false
+			}
+		}
+		return true
+	case bytecode.PatternKindNamed:
+		locals[p.name] = x
+		return true
+	case bytecode.PatternKindRecord:
+		rec := x.(vmRecord)
+		for _, name := range p.nested {
+			locals[name.(string)] = rec[name.(string)]
+		}
+		return true
+	case bytecode.PatternKindTuple:
+		for i, item := range x.(vmTuple) {
+			if !vm.match(p.nested[i].(vmPattern), item, locals) {
+				return false
+			}
+		}
+		return true
+	}
+	panic(vmTrap(fmt.Sprintf("unknown pattern kind %d", p.kind)))
+}
+
+func (vm *testVM) native(name string, args []any) any {
+	switch name {
+	case "Nar.Base.Math.neg":
+		return vmArith(args[0], args[0], func(_, b int64) int64 { return -b }, func(_, b float64) float64 { return -b })
+	case "Nar.Base.Math.add":
+		return vmArith(args[0], args[1], func(a, b int64) int64 { return a + b }, func(a, b float64) float64 { return a + b })
+	case "Nar.Base.Math.sub":
+		return vmArith(args[0], args[1], func(a, b int64) int64 { return a - b }, func(a, b float64) float64 { return a - b })
+	case "Nar.Base.Math.mul":
+		return vmArith(args[0], args[1], func(a, b int64) int64 { return a * b }, func(a, b float64) float64 { return a * b })
+	case "Nar.Base.Math.eq":
+		return vmBool(reflect.DeepEqual(args[0], args[1]))
+	case "Nar.Base.Math.lt":
+		switch a := args[0].(type) {
+		case int64:
+			return vmBool(a < args[1].(int64))
+		case float64:
+			return vmBool(a < args[1].(float64))
+		}
+	case "Nar.Base.List.cons":
+		return append([]any{args[0]}, args[1].([]any)...)
+	case "Nar.Base.List.map":
+		var result []any
+		for _, x := range args[1].([]any) {
+			result = append(result, vm.apply(args[0], []any{x}))
+		}
+		return result
+	case "Nar.Base.String.append":
+		return args[0].(string) + args[1].(string)
+	case "Nar.Base.String.fromInt":
+		return strconv.FormatInt(args[0].(int64), 10)
+	case "Nar.Base.String.show":
+		if s, ok := args[0].(string); ok {
+			return s
+		}
+		return vmFormat(args[0])
+	}
+	panic(vmTrap(fmt.Sprintf("native `%s` is not implemented", name)))
+}
+
+func vmArith(a, b any, i func(a, b int64) int64, f func(a, b float64) float64) any {
+	if x, ok := a.(float64); ok {
+		return f(x, b.(float64))
+	}
+	return i(a.(int64), b.(int64))
+}
+
+func vmBool(b bool) vmOption {
+	if b {
+		return vmTrue
+	}
+	return vmFalse
+}
+
+// vmFormat returns string representation of the object, data options are printed without type name
+func vmFormat(x any) string {
+	join := func(items []any) string {
+		var ss []string
+		for _, item := range items {
+			ss = append(ss, vmFormat(item))
+		}
+		return strings.Join(ss, ", ")
+	}
+	switch x := x.(type) {
+	case vmUnit:
+		return "()"
+	case rune:
+		return strconv.QuoteRune(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	case string:
+		return strconv.Quote(x)
+	case []any:
+		return "[" + join(x) + "]"
+	case vmTuple:
+		return "(" + join(x) + ")"
+	case vmRecord:
+		var names []string
+		for name := range x {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		var ss []string
+		for _, name := range names {
+			ss = append(ss, name+" = "+vmFormat(x[name]))
+		}
+		return "{" + strings.Join(ss, ", ") + "}"
+	case vmOption:
+		_, name, _ := strings.Cut(x.name, "#")
+		if len(x.args) == 0 {
+			return name
+		}
+		return name + "(" + join(x.args) + ")"
+	case vmClosure:
+		return "<closure>"
+	}
+	return fmt.Sprintf("<%T>", x)
+}
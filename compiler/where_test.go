@@ -0,0 +1,36 @@
+package compiler
+
+import "testing"
+
+func TestWhereClauses(t *testing.T) {
+	src := `
+module Test
+
+import Nar.Base.Basics exposing *
+import Nar.Base.Math exposing *
+
+def ordered(y: Int): Int = helper(y)
+  where k = 100,
+    helper(z: Int): Int = z * 2 + k
+
+def shadowed(x: Int): Int = k
+  where k = x + 1,
+    k = k * 10
+
+def recursive(n: Int): Int = sum(n)
+  where sum(i: Int): Int = if i == 0 then 0 else i + sum(i - 1)
+
+def mutual(n: Int): (Bool, Bool) = (isEven(n), isOdd(n))
+  where isEven(i: Int): Bool = if i == 0 then True else isOdd(i - 1),
+    isOdd(i: Int): Bool = if i == 0 then False else isEven(i - 1)
+
+def main = (ordered(1), shadowed(2), recursive(4), mutual(7))
+`
+	for _, optimize := range []bool{false, true} {
+		r := mustCompile(t, src, optimize)
+		expected := "(102, 30, 10, (False, True))"
+		if got := r.run(t, "main"); got != expected {
+			t.Errorf("optimize=%v: expected %s, got %s", optimize, expected, got)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package linker
+
+import (
+	"github.com/nar-lang/nar-compiler/bytecode"
+	"github.com/nar-lang/nar-compiler/locator"
+	"github.com/nar-lang/nar-compiler/logger"
+)
+
+// NewShakingLinker creates linker that removes functions unreachable from the entry point
+// and given exports before passing binary to the next linker
+func NewShakingLinker(next Linker, exports ...bytecode.FullIdentifier) Linker {
+	return &shakingLinker{next: next, exports: exports}
+}
+
+type shakingLinker struct {
+	next    Linker
+	exports []bytecode.FullIdentifier
+}
+
+func (l *shakingLinker) Link(log *logger.LogWriter, binary *bytecode.Binary, lc locator.Locator, debug bool) error {
+	entry, err := lc.EntryPoint()
+	if err != nil {
+		return err
+	}
+	binary.Entry = entry
+
+	roots := l.exports
+	if entry != "" {
+		roots = append([]bytecode.FullIdentifier{entry}, roots...)
+	}
+	if len(roots) > 0 {
+		if err := binary.Shake(roots); err != nil {
+			return err
+		}
+	}
+	return l.next.Link(log, binary, lc, debug)
+}
@@ -0,0 +1,276 @@
+package locator
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// NewArchivePackageProvider creates provider that loads package from .zip, .tar.gz or .tgz archive.
+// Archive contains nar.json, src and native directories in the root or in a single top level directory.
+func NewArchivePackageProvider(path string) Provider {
+	return &archiveProvider{path: path}
+}
+
+type archiveProvider struct {
+	path string
+	pkg  *archivePackage
+}
+
+type archiveFile struct {
+	name    string
+	content []byte
+	mode    os.FileMode
+}
+
+func (a *archiveProvider) ExportedPackages() ([]Package, error) {
+	if err := a.load(); err != nil {
+		return nil, err
+	}
+	return []Package{a.pkg}, nil
+}
+
+func (a *archiveProvider) LoadPackage(name string) (Package, bool, error) {
+	if err := a.load(); err != nil {
+		return nil, false, err
+	}
+	if a.pkg.Info().Name == name {
+		return a.pkg, true, nil
+	}
+	return nil, false, nil
+}
+
+func (a *archiveProvider) load() error {
+	if a.pkg != nil {
+		return nil
+	}
+	files, err := readArchive(a.path)
+	if err != nil {
+		return fmt.Errorf("failed to read archive %s: %w", a.path, err)
+	}
+	pkg, err := newArchivePackage(a.path, files)
+	if err != nil {
+		return err
+	}
+	a.pkg = pkg
+	return nil
+}
+
+func isArchive(path string) bool {
+	return strings.HasSuffix(path, ".zip") || strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+func readArchive(path string) ([]archiveFile, error) {
+	switch {
+	case strings.HasSuffix(path, ".zip"):
+		return readZip(path)
+	case strings.HasSuffix(path, ".tar.gz"), strings.HasSuffix(path, ".tgz"):
+		return readTarGz(path)
+	default:
+		return nil, fmt.Errorf("unsupported archive format")
+	}
+}
+
+func readZip(path string) ([]archiveFile, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var files []archiveFile
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, archiveFile{name: f.Name, content: content, mode: f.Mode()})
+	}
+	return files, nil
+}
+
+func readTarGz(path string) ([]archiveFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var files []archiveFile
+	r := tar.NewReader(gz)
+	for {
+		header, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, archiveFile{name: header.Name, content: content, mode: header.FileInfo().Mode()})
+	}
+	return files, nil
+}
+
+// archivePackage is a package loaded from archive, native files are extracted on demand
+type archivePackage struct {
+	loadedPackage
+	natives     map[string][]archiveFile
+	extracted   map[string][]string
+	contentHash string
+	signature   []byte
+}
+
+func newArchivePackage(archivePath string, files []archiveFile) (*archivePackage, error) {
+	prefix, ok := archiveRoot(files)
+	if !ok {
+		return nil, fmt.Errorf("archive %s does not contain nar.json", archivePath)
+	}
+
+	pkg := &archivePackage{
+		loadedPackage: loadedPackage{path: archivePath},
+		natives:       map[string][]archiveFile{},
+		extracted:     map[string][]string{},
+	}
+	hashed := map[string][]byte{}
+	for _, f := range files {
+		name := path.Clean(strings.TrimPrefix(f.name, "./"))
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		name = strings.TrimPrefix(name, prefix)
+		if isHashedFile(name) {
+			hashed[name] = f.content
+		}
+		switch {
+		case name == signatureFileName:
+			signature, err := decodeSignature(f.content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(archivePath, name), err)
+			}
+			pkg.signature = signature
+		case name == "nar.json":
+			if err := json.Unmarshal(f.content, &pkg.info); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal %s: %w", filepath.Join(archivePath, name), err)
+			}
+		case strings.HasPrefix(name, "src/") && path.Ext(name) == ".nar":
+			sourcePath := filepath.Join(archivePath, filepath.FromSlash(name))
+			pkg.sources = append(pkg.sources, newDecodedModuleSource(sourcePath, f.content))
+		case strings.HasPrefix(name, "native/"):
+			platform, rel, ok := strings.Cut(strings.TrimPrefix(name, "native/"), "/")
+			if ok && !strings.Contains(rel, "..") {
+				pkg.natives[platform] = append(pkg.natives[platform], archiveFile{name: rel, content: f.content, mode: f.mode})
+			}
+		}
+	}
+	slices.SortFunc(pkg.sources, func(a, b ModuleSource) int {
+		return strings.Compare(a.FilePath(), b.FilePath())
+	})
+	pkg.contentHash = hashFiles(hashed)
+	return pkg, nil
+}
+
+func (a *archivePackage) ContentHash() (string, error) {
+	return a.contentHash, nil
+}
+
+func (a *archivePackage) Signature() ([]byte, error) {
+	return a.signature, nil
+}
+
+// archiveRoot returns directory prefix of nar.json, it should be either archive root or a top level directory
+func archiveRoot(files []archiveFile) (string, bool) {
+	for _, f := range files {
+		name := path.Clean(strings.TrimPrefix(f.name, "./"))
+		if name == "nar.json" {
+			return "", true
+		}
+	}
+	for _, f := range files {
+		name := path.Clean(strings.TrimPrefix(f.name, "./"))
+		if dir, file := path.Split(name); file == "nar.json" && strings.Count(dir, "/") == 1 {
+			return dir, true
+		}
+	}
+	return "", false
+}
+
+// NativeFilePaths extracts native files of the platform to the user cache directory and returns their paths.
+// Directory is named by package content hash, so files are extracted once and reused by following builds.
+func (a *archivePackage) NativeFilePaths(platform string) ([]string, error) {
+	if paths, ok := a.extracted[platform]; ok {
+		return paths, nil
+	}
+	files := a.natives[platform]
+	if len(files) == 0 || !isValidPackageName(platform) {
+		return nil, nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	dir := filepath.Join(cacheDir, "nar", "natives", a.contentHash, platform)
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = filepath.Join(dir, filepath.FromSlash(f.name))
+	}
+	if _, err := os.Stat(dir); err == nil {
+		a.extracted[platform] = paths
+		return paths, nil
+	}
+
+	//files are extracted to temporary directory first, so partially extracted directory is never used
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for native files: %w", err)
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), ".extract-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create directory for native files: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	for _, f := range files {
+		filePath := filepath.Join(tmp, filepath.FromSlash(f.name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory for native files: %w", err)
+		}
+		if err := os.WriteFile(filePath, f.content, f.mode.Perm()|0200); err != nil {
+			return nil, fmt.Errorf("failed to extract native file: %w", err)
+		}
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		//directory could be extracted by another process meanwhile
+		if _, statErr := os.Stat(dir); statErr != nil {
+			return nil, fmt.Errorf("failed to extract native files: %w", err)
+		}
+	}
+	a.extracted[platform] = paths
+	return paths, nil
+}
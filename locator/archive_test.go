@@ -0,0 +1,98 @@
+package locator
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeTestZip writes zip archive with given files and returns its path
+func writeTestZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "package.zip")
+	out, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(out)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+func TestArchiveNativeFilePaths(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	t.Setenv("HOME", cacheDir)
+	t.Setenv("LocalAppData", cacheDir)
+
+	archivePath := writeTestZip(t, map[string]string{
+		"pkg/nar.json":              `{"name": "scope/pkg", "version": "1.0.0"}`,
+		"pkg/src/Test.nar":          "module Test\n",
+		"pkg/native/dll/lib.so":     "lib",
+		"pkg/native/dll/sub/dep.so": "dep",
+	})
+	pkg, ok, err := NewArchivePackageProvider(archivePath).LoadPackage("scope/pkg")
+	if err != nil || !ok {
+		t.Fatalf("package is not loaded: %v", err)
+	}
+
+	paths, err := pkg.NativeFilePaths("dll")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(paths)
+	if len(paths) != 2 || !strings.HasPrefix(paths[0], cacheDir) {
+		t.Fatalf("unexpected native file paths %v", paths)
+	}
+	for i, expected := range []string{"lib", "dep"} {
+		content, err := os.ReadFile(paths[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != expected {
+			t.Errorf("%s: expected `%s`, got `%s`", paths[i], expected, content)
+		}
+	}
+
+	//the same package loaded again reuses extracted files
+	reloaded, _, err := NewArchivePackageProvider(archivePath).LoadPackage("scope/pkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := reloaded.NativeFilePaths("dll")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(again)
+	if !slices.Equal(paths, again) {
+		t.Errorf("expected the same paths %v, got %v", paths, again)
+	}
+	entries, err := os.ReadDir(filepath.Dir(filepath.Dir(paths[0])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary directories are left: %v", entries)
+	}
+
+	if paths, err := pkg.NativeFilePaths("missing"); err != nil || paths != nil {
+		t.Errorf("expected no files for missing platform, got %v, %v", paths, err)
+	}
+}
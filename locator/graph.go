@@ -0,0 +1,105 @@
+package locator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ResolutionGraph is a graph of resolved packages, starting from root packages
+type ResolutionGraph struct {
+	Roots    []*ResolvedPackage
+	packages map[string]*ResolvedPackage
+}
+
+type ResolvedPackage struct {
+	Package      Package
+	Dependencies []ResolvedDependency
+}
+
+// ResolvedDependency is an edge of resolution graph. Constraint is the one declared by dependent package,
+// Override is set if constraint was replaced by root package overrides
+type ResolvedDependency struct {
+	Name       string
+	Constraint Constraint
+	Override   *Constraint
+	Package    *ResolvedPackage
+}
+
+func newResolutionGraph(r *resolver, packages map[string]Package) *ResolutionGraph {
+	nodes := map[string]*ResolvedPackage{}
+	for name, pkg := range packages {
+		nodes[name] = &ResolvedPackage{Package: pkg}
+	}
+	for _, node := range nodes {
+		info := node.Package.Info()
+		for depName, constraint := range info.Dependencies {
+			edge := ResolvedDependency{Name: depName, Constraint: constraint}
+			if isRelativeDependency(depName) {
+				edge.Package = nodes[r.relativeName(node.Package, depName)]
+			} else {
+				edge.Package = nodes[depName]
+				if override, ok := r.overrides[depName]; ok {
+					edge.Override = &override.constraint
+				}
+			}
+			//dependencies that were not resolved, e.g. relative ones of packages without path, are skipped
+			if edge.Package == nil {
+				continue
+			}
+			node.Dependencies = append(node.Dependencies, edge)
+		}
+		slices.SortFunc(node.Dependencies, func(a, b ResolvedDependency) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	}
+
+	g := &ResolutionGraph{packages: nodes}
+	for _, root := range r.roots {
+		g.Roots = append(g.Roots, nodes[root.Info().Name])
+	}
+	return g
+}
+
+// Find returns resolved package by name
+func (g *ResolutionGraph) Find(name string) (*ResolvedPackage, bool) {
+	node, ok := g.packages[name]
+	return node, ok
+}
+
+// Tree returns text representation of the graph. Packages that are already shown are marked
+// and their dependencies are not repeated.
+func (g *ResolutionGraph) Tree() string {
+	sb := strings.Builder{}
+	shown := map[*ResolvedPackage]struct{}{}
+
+	var write func(node *ResolvedPackage, prefix string)
+	write = func(node *ResolvedPackage, prefix string) {
+		for i, dep := range node.Dependencies {
+			branch, indent := "├── ", "│   "
+			if i == len(node.Dependencies)-1 {
+				branch, indent = "└── ", "    "
+			}
+			constraint := dep.Constraint.String()
+			if dep.Override != nil {
+				constraint = fmt.Sprintf("%s, overridden %s", constraint, dep.Override)
+			}
+			sb.WriteString(fmt.Sprintf("%s%s%s %s (%s)", prefix, branch,
+				dep.Package.Package.Info().Name, dep.Package.Package.Info().Version, constraint))
+			if _, ok := shown[dep.Package]; ok && len(dep.Package.Dependencies) > 0 {
+				sb.WriteString(" (shown above)\n")
+				continue
+			}
+			sb.WriteString("\n")
+			shown[dep.Package] = struct{}{}
+			write(dep.Package, prefix+indent)
+		}
+	}
+
+	for _, root := range g.Roots {
+		shown[root] = struct{}{}
+		sb.WriteString(fmt.Sprintf("%s %s\n", root.Package.Info().Name, root.Package.Info().Version))
+		write(root, "")
+	}
+	return sb.String()
+}
@@ -0,0 +1,60 @@
+package locator
+
+import (
+	"testing"
+)
+
+func TestResolutionGraph(t *testing.T) {
+	provider := newTestVersionsProvider(t,
+		"a@1.0.0", "a@1.2.0",
+		"b@1.1.0: a@~1.2.0",
+		"c@1.0.0: a@^1.0.0, b@^1.0.0")
+	info := parseTestPackage(t, "root@1.0.0: b@^1.0.0, c@^1.0.0")
+	override, err := ParseConstraint("1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info.Overrides = map[string]Constraint{"a": override}
+	root := NewLoadedPackage(info, nil, "")
+
+	r := newResolver([]Provider{provider}, []Package{root}, nil)
+	packages, err := r.resolve()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := newResolutionGraph(r, packages)
+
+	expected := `root 1.0.0
+├── b 1.1.0 (^1.0.0)
+│   └── a 1.0.0 (~1.2.0, overridden 1.0.0)
+└── c 1.0.0 (^1.0.0)
+    ├── a 1.0.0 (^1.0.0, overridden 1.0.0)
+    └── b 1.1.0 (^1.0.0) (shown above)
+`
+	if got := g.Tree(); got != expected {
+		t.Errorf("expected tree:\n%s\ngot:\n%s", expected, got)
+	}
+
+	c, ok := g.Find("c")
+	if !ok {
+		t.Fatal("package `c` is not found")
+	}
+	if len(c.Dependencies) != 2 || c.Dependencies[0].Name != "a" || c.Dependencies[0].Override == nil {
+		t.Errorf("unexpected dependencies of `c`: %v", c.Dependencies)
+	}
+	if _, ok := g.Find("d"); ok {
+		t.Errorf("package `d` should not be found")
+	}
+}
+
+func TestResolutionGraphSkipsUnresolvedDependencies(t *testing.T) {
+	root := NewLoadedPackage(parseTestPackage(t, "root@1.0.0: ./missing@*"), nil, "")
+	r := newResolver(nil, []Package{root}, nil)
+	g := newResolutionGraph(r, map[string]Package{"root": root})
+	if len(g.Roots[0].Dependencies) != 0 {
+		t.Errorf("unresolved dependency should be skipped: %v", g.Roots[0].Dependencies)
+	}
+	if got := g.Tree(); got != "root 1.0.0\n" {
+		t.Errorf("unexpected tree:\n%s", got)
+	}
+}
@@ -0,0 +1,293 @@
+package locator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// PackageIndex is a list of package versions served by HTTP registry at `<base url>/<name>/index.json`
+type PackageIndex struct {
+	Name     string              `json:"name"`
+	Versions []PackageIndexEntry `json:"versions"`
+}
+
+// PackageIndexEntry describes package archive. Archive URL can be relative to the index URL.
+// Hash is a sha256 hash of archive file in form of `sha256:<hex>`.
+// Dependencies let resolver select versions without downloading archives,
+// version without dependencies in the index is downloaded to read them from nar.json.
+type PackageIndexEntry struct {
+	Version      Version               `json:"version"`
+	Hash         string                `json:"hash"`
+	Archive      string                `json:"archive"`
+	Dependencies map[string]Constraint `json:"dependencies"`
+}
+
+// NewHTTPRegistryProvider creates provider that downloads packages from HTTP registry to the local registry cache.
+// Packages that are already in the cache are not downloaded again. Nil client means http.DefaultClient.
+func NewHTTPRegistryProvider(baseURL string, cacheRoot string, client *http.Client) VersionedProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &httpRegistryProvider{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+		cache:   NewRegistryProvider(cacheRoot).(*registryProvider),
+		indices: map[string]*PackageIndex{},
+	}
+}
+
+type httpRegistryProvider struct {
+	baseURL string
+	client  *http.Client
+	cache   *registryProvider
+	indices map[string]*PackageIndex
+}
+
+func (h *httpRegistryProvider) ExportedPackages() ([]Package, error) {
+	return nil, nil
+}
+
+func (h *httpRegistryProvider) LoadPackage(name string) (Package, bool, error) {
+	return h.LoadPackageInRange(name, Constraint{})
+}
+
+func (h *httpRegistryProvider) LoadPackageInRange(name string, constraint Constraint) (Package, bool, error) {
+	index, err := h.loadIndex(name)
+	if err != nil {
+		return nil, false, err
+	}
+	var best *PackageIndexEntry
+	for i, entry := range index.Versions {
+		if constraint.Allows(entry.Version) && (best == nil || entry.Version.Compare(best.Version) > 0) {
+			best = &index.Versions[i]
+		}
+	}
+	if best == nil {
+		return nil, false, nil
+	}
+	pkg, err := h.fetch(name, *best)
+	if err != nil {
+		return nil, false, err
+	}
+	return pkg, true, nil
+}
+
+// LoadPackageVersions returns versions of the package listed in the index sorted from the lowest to the highest.
+// Archives are downloaded only when the version is fetched after resolution.
+func (h *httpRegistryProvider) LoadPackageVersions(name string) ([]Package, error) {
+	index, err := h.loadIndex(name)
+	if err != nil {
+		return nil, err
+	}
+	var versions []Package
+	for _, entry := range index.Versions {
+		if entry.Dependencies == nil {
+			pkg, err := h.fetch(name, entry)
+			if err != nil {
+				return nil, err
+			}
+			versions = append(versions, pkg)
+			continue
+		}
+		versions = append(versions, &indexedPackage{
+			provider: h,
+			entry:    entry,
+			info:     PackageInfo{Name: name, Version: entry.Version, Dependencies: entry.Dependencies},
+		})
+	}
+	slices.SortFunc(versions, func(a, b Package) int {
+		return a.Info().Version.Compare(b.Info().Version)
+	})
+	return versions, nil
+}
+
+// fetch downloads package version to the cache and loads it
+func (h *httpRegistryProvider) fetch(name string, entry PackageIndexEntry) (Package, error) {
+	if err := h.download(name, entry); err != nil {
+		return nil, err
+	}
+	pkg, ok, err := h.cache.LoadPackageInRange(name, ExactConstraint(entry.Version))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("package `%s` %s is not found in %s", name, entry.Version, h.cache.root)
+	}
+	return pkg, nil
+}
+
+func (h *httpRegistryProvider) loadIndex(name string) (*PackageIndex, error) {
+	if index, ok := h.indices[name]; ok {
+		return index, nil
+	}
+	if !isValidPackageName(name) {
+		return &PackageIndex{Name: name}, nil
+	}
+	indexURL := fmt.Sprintf("%s/%s/index.json", h.baseURL, url.PathEscape(name))
+	rsp, err := h.client.Get(indexURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load package index %s: %w", indexURL, err)
+	}
+	defer rsp.Body.Close()
+
+	index := &PackageIndex{Name: name}
+	switch rsp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(rsp.Body).Decode(index); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal package index %s: %w", indexURL, err)
+		}
+		if index.Name != name {
+			return nil, fmt.Errorf("package index %s describes package `%s`", indexURL, index.Name)
+		}
+	case http.StatusNotFound:
+	default:
+		return nil, fmt.Errorf("failed to load package index %s: %s", indexURL, rsp.Status)
+	}
+	h.indices[name] = index
+	return index, nil
+}
+
+// download fetches package archive, verifies its hash and installs it to the cache
+func (h *httpRegistryProvider) download(name string, entry PackageIndexEntry) error {
+	if _, err := os.Stat(filepath.Join(h.cache.root, name, entry.Version.String())); err == nil {
+		return nil
+	}
+
+	indexURL, err := url.Parse(fmt.Sprintf("%s/%s/index.json", h.baseURL, url.PathEscape(name)))
+	if err != nil {
+		return err
+	}
+	archiveURL, err := indexURL.Parse(entry.Archive)
+	if err != nil {
+		return fmt.Errorf("invalid archive url of package `%s` %s: %w", name, entry.Version, err)
+	}
+	if !isArchive(archiveURL.Path) {
+		return fmt.Errorf("unsupported archive format of package `%s` %s: %s", name, entry.Version, archiveURL)
+	}
+
+	rsp, err := h.client.Get(archiveURL.String())
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", archiveURL, err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", archiveURL, rsp.Status)
+	}
+
+	ext := path.Ext(archiveURL.Path)
+	if strings.HasSuffix(archiveURL.Path, ".tar.gz") {
+		ext = ".tar.gz"
+	}
+	file, err := os.CreateTemp("", "nar-download-*"+ext)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", archiveURL, err)
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	h256 := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, h256), rsp.Body); err != nil {
+		return fmt.Errorf("failed to download %s: %w", archiveURL, err)
+	}
+	if hash := "sha256:" + hex.EncodeToString(h256.Sum(nil)); hash != entry.Hash {
+		return fmt.Errorf("hash mismatch of package `%s` %s: expected %s, got %s", name, entry.Version, entry.Hash, hash)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to download %s: %w", archiveURL, err)
+	}
+
+	packages, err := NewArchivePackageProvider(file.Name()).ExportedPackages()
+	if err != nil {
+		return err
+	}
+	if info := packages[0].Info(); info.Name != name || info.Version.Compare(entry.Version) != 0 {
+		return fmt.Errorf("archive %s contains package `%s` %s", archiveURL, info.Name, info.Version)
+	}
+	if _, err := InstallArchive(h.cache.root, file.Name()); err != nil {
+		return err
+	}
+	delete(h.cache.versions, name)
+	return nil
+}
+
+// indexedPackage is a package version described by the registry index.
+// Its archive is downloaded on the first access to the package content.
+type indexedPackage struct {
+	provider *httpRegistryProvider
+	entry    PackageIndexEntry
+	info     PackageInfo
+	fetched  Package
+	err      error
+}
+
+func (p *indexedPackage) fetch() (Package, error) {
+	if p.fetched != nil || p.err != nil {
+		return p.fetched, p.err
+	}
+	p.fetched, p.err = p.provider.fetch(p.info.Name, p.entry)
+	if p.err == nil && !maps.EqualFunc(p.fetched.Info().Dependencies, p.entry.Dependencies,
+		func(a, b Constraint) bool { return a.String() == b.String() }) {
+		p.fetched, p.err = nil, fmt.Errorf(
+			"dependencies of package `%s` %s differ from the registry index", p.info.Name, p.info.Version)
+	}
+	return p.fetched, p.err
+}
+
+func (p *indexedPackage) Info() PackageInfo {
+	return p.info
+}
+
+func (p *indexedPackage) SetInfo(info PackageInfo) {
+	p.info = info
+}
+
+func (p *indexedPackage) Sources() []ModuleSource {
+	pkg, err := p.fetch()
+	if err != nil {
+		return nil
+	}
+	return pkg.Sources()
+}
+
+func (p *indexedPackage) NativeFilePaths(platform string) ([]string, error) {
+	pkg, err := p.fetch()
+	if err != nil {
+		return nil, err
+	}
+	return pkg.NativeFilePaths(platform)
+}
+
+func (p *indexedPackage) Path() string {
+	pkg, err := p.fetch()
+	if err != nil {
+		return ""
+	}
+	return pkg.Path()
+}
+
+func (p *indexedPackage) ContentHash() (string, error) {
+	pkg, err := p.fetch()
+	if err != nil {
+		return "", err
+	}
+	return pkg.ContentHash()
+}
+
+func (p *indexedPackage) Signature() ([]byte, error) {
+	pkg, err := p.fetch()
+	if err != nil {
+		return nil, err
+	}
+	return pkg.Signature()
+}
@@ -0,0 +1,96 @@
+package locator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newTestRegistry serves index of package `lib` and archives of its versions, counting archive downloads
+func newTestRegistry(t *testing.T, versions ...string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	archives := map[string][]byte{}
+	index := PackageIndex{Name: "lib"}
+	for _, s := range versions {
+		info := parseTestPackage(t, s)
+		infoJson, err := json.Marshal(info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(writeTestZip(t, map[string]string{
+			"lib/nar.json":    string(infoJson),
+			"lib/src/Lib.nar": "module Lib\n",
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash := sha256.Sum256(data)
+		name := fmt.Sprintf("lib-%s.zip", info.Version)
+		archives["/lib/"+name] = data
+		index.Versions = append(index.Versions, PackageIndexEntry{
+			Version:      info.Version,
+			Hash:         "sha256:" + hex.EncodeToString(hash[:]),
+			Archive:      name,
+			Dependencies: info.Dependencies,
+		})
+	}
+
+	var downloads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/lib/index.json" {
+			_ = json.NewEncoder(w).Encode(index)
+			return
+		}
+		if data, ok := archives[r.URL.Path]; ok {
+			downloads.Add(1)
+			_, _ = w.Write(data)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &downloads
+}
+
+func TestHTTPRegistryDownloadsSelectedVersion(t *testing.T) {
+	server, downloads := newTestRegistry(t, "lib@1.0.0", "lib@1.2.0", "lib@1.10.0", "lib@2.0.0")
+	root := NewMemoryPackageProvider(parseTestPackage(t, "app@1.0.0: lib@^1.0.0"), nil)
+	registry := NewHTTPRegistryProvider(server.URL, t.TempDir(), server.Client())
+
+	lib, ok, err := NewLocator(root, registry).FindPackage("lib")
+	if err != nil || !ok {
+		t.Fatalf("package is not found: %v", err)
+	}
+	if v := lib.Info().Version.String(); v != "1.10.0" {
+		t.Errorf("expected version 1.10.0, got %s", v)
+	}
+	if n := downloads.Load(); n != 1 {
+		t.Errorf("expected 1 archive download, got %d", n)
+	}
+	if len(lib.Sources()) != 1 {
+		t.Errorf("expected 1 source, got %d", len(lib.Sources()))
+	}
+}
+
+func TestHTTPRegistryHashMismatch(t *testing.T) {
+	server, _ := newTestRegistry(t, "lib@1.0.0")
+	registry := NewHTTPRegistryProvider(server.URL, t.TempDir(), server.Client()).(*httpRegistryProvider)
+	index, err := registry.loadIndex("lib")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := index.Versions[0]
+	entry.Hash = "sha256:00"
+
+	_, err = registry.fetch("lib", entry)
+	if err == nil || !strings.Contains(err.Error(), "hash mismatch") {
+		t.Errorf("expected hash mismatch error, got %v", err)
+	}
+}
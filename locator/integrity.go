@@ -0,0 +1,257 @@
+package locator
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// signatureFileName is a name of file in the package root that contains base64 encoded ed25519 signature
+// of the package content hash
+const signatureFileName = "nar.sig"
+
+// isHashedFile tells if file with given slash separated path relative to the package root
+// is a part of the package content hash
+func isHashedFile(name string) bool {
+	return name == "nar.json" ||
+		(strings.HasPrefix(name, "src/") && path.Ext(name) == ".nar") ||
+		strings.HasPrefix(name, "native/")
+}
+
+// hashFiles returns sha256 hash of files, keys are slash separated paths relative to the package root
+func hashFiles(files map[string][]byte) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	h := sha256.New()
+	for _, name := range names {
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write(files[name])
+		h.Write([]byte{0})
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// ContentHash hashes package files on disk once. Packages without path are hashed by their sources.
+func (l *loadedPackage) ContentHash() (string, error) {
+	if l.contentHash != "" {
+		return l.contentHash, nil
+	}
+	files := map[string][]byte{}
+	if l.path == "" {
+		for _, source := range l.sources {
+			content, err := source.Content()
+			if err != nil {
+				return "", err
+			}
+			files[filepath.ToSlash(source.FilePath())] = []byte(string(content))
+		}
+		l.contentHash = hashFiles(files)
+		return l.contentHash, nil
+	}
+	err := filepath.WalkDir(l.path, func(filePath string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(l.path, filePath)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !isHashedFile(rel) {
+			return nil
+		}
+		content, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		files[rel] = content
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash package `%s`: %w", l.info.Name, err)
+	}
+	l.contentHash = hashFiles(files)
+	return l.contentHash, nil
+}
+
+func (l *loadedPackage) Signature() ([]byte, error) {
+	if l.path == "" {
+		return nil, nil
+	}
+	sigPath := filepath.Join(l.path, signatureFileName)
+	content, err := os.ReadFile(sigPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", sigPath, err)
+	}
+	signature, err := decodeSignature(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", sigPath, err)
+	}
+	return signature, nil
+}
+
+func decodeSignature(content []byte) ([]byte, error) {
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(content)))
+	if err != nil {
+		return nil, err
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return nil, fmt.Errorf("invalid signature size")
+	}
+	return signature, nil
+}
+
+// SignPackage returns content of nar.sig file that signs the package with the key
+func SignPackage(pkg Package, key ed25519.PrivateKey) (string, error) {
+	hash, err := pkg.ContentHash()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(hash))) + "\n", nil
+}
+
+// VerifyPackage checks that package is signed by one of the trusted keys.
+// Unsigned packages are accepted unless signature is required.
+func VerifyPackage(pkg Package, trustedKeys []ed25519.PublicKey, required bool) error {
+	signature, err := pkg.Signature()
+	if err != nil {
+		return err
+	}
+	if signature == nil {
+		if required {
+			return fmt.Errorf("package `%s` %s is not signed", pkg.Info().Name, pkg.Info().Version)
+		}
+		return nil
+	}
+	hash, err := pkg.ContentHash()
+	if err != nil {
+		return err
+	}
+	for _, key := range trustedKeys {
+		if ed25519.Verify(key, []byte(hash), signature) {
+			return nil
+		}
+	}
+	return fmt.Errorf("package `%s` %s is not signed with a trusted key", pkg.Info().Name, pkg.Info().Version)
+}
+
+// NewVerifyingProvider creates provider that checks signatures of packages loaded by the next provider
+func NewVerifyingProvider(next Provider, trustedKeys []ed25519.PublicKey, required bool) VersionedProvider {
+	return &verifyingProvider{next: next, trustedKeys: trustedKeys, required: required}
+}
+
+type verifyingProvider struct {
+	next        Provider
+	trustedKeys []ed25519.PublicKey
+	required    bool
+}
+
+func (v *verifyingProvider) verify(packages ...Package) error {
+	for _, pkg := range packages {
+		if err := VerifyPackage(pkg, v.trustedKeys, v.required); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *verifyingProvider) ExportedPackages() ([]Package, error) {
+	packages, err := v.next.ExportedPackages()
+	if err != nil {
+		return nil, err
+	}
+	if err := v.verify(packages...); err != nil {
+		return nil, err
+	}
+	return packages, nil
+}
+
+func (v *verifyingProvider) LoadPackage(name string) (Package, bool, error) {
+	pkg, ok, err := v.next.LoadPackage(name)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	if err := v.verify(pkg); err != nil {
+		return nil, false, err
+	}
+	return pkg, true, nil
+}
+
+func (v *verifyingProvider) LoadPackageVersions(name string) ([]Package, error) {
+	versioned, ok := v.next.(VersionedProvider)
+	if !ok {
+		pkg, ok, err := v.LoadPackage(name)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return []Package{pkg}, nil
+	}
+	packages, err := versioned.LoadPackageVersions(name)
+	if err != nil {
+		return nil, err
+	}
+	for i, pkg := range packages {
+		if f, ok := pkg.(fetcher); ok {
+			packages[i] = &verifyingFetcher{Package: pkg, fetcher: f, provider: v}
+		} else if err := v.verify(pkg); err != nil {
+			return nil, err
+		}
+	}
+	return packages, nil
+}
+
+func (v *verifyingProvider) LoadPackageInRange(name string, constraint Constraint) (Package, bool, error) {
+	versioned, ok := v.next.(VersionedProvider)
+	if !ok {
+		pkg, ok, err := v.LoadPackage(name)
+		if err != nil || !ok || !constraint.Allows(pkg.Info().Version) {
+			return nil, false, err
+		}
+		return pkg, true, nil
+	}
+	pkg, ok, err := versioned.LoadPackageInRange(name, constraint)
+	if err != nil || !ok {
+		return nil, ok, err
+	}
+	if err := v.verify(pkg); err != nil {
+		return nil, false, err
+	}
+	return pkg, true, nil
+}
+
+// verifyingFetcher checks signature of the package when it is fetched
+type verifyingFetcher struct {
+	Package
+	fetcher  fetcher
+	provider *verifyingProvider
+}
+
+func (f *verifyingFetcher) fetch() (Package, error) {
+	pkg, err := f.fetcher.fetch()
+	if err != nil {
+		return nil, err
+	}
+	if err := f.provider.verify(pkg); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
@@ -0,0 +1,146 @@
+package locator
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestPackage writes package directory with given files and returns its path
+func writeTestPackage(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		filePath := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func loadTestPackage(t *testing.T, root string) Package {
+	t.Helper()
+	packages, err := NewFileSystemPackageProvider(root).ExportedPackages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return packages[0]
+}
+
+func newTestKey(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return public, private
+}
+
+func TestSignAndVerifyPackage(t *testing.T) {
+	trusted, key := newTestKey(t)
+	untrusted, otherKey := newTestKey(t)
+
+	root := writeTestPackage(t, map[string]string{
+		"nar.json":        `{"name": "lib", "version": "1.0.0"}`,
+		"src/Lib.nar":     "module Lib\n",
+		"native/dll/a.so": "a",
+		"README.md":       "not hashed",
+	})
+	sign := func(key ed25519.PrivateKey) {
+		signature, err := SignPackage(loadTestPackage(t, root), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, signatureFileName), []byte(signature), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	verify := func(keys []ed25519.PublicKey, required bool) error {
+		return VerifyPackage(loadTestPackage(t, root), keys, required)
+	}
+
+	if err := verify(nil, false); err != nil {
+		t.Errorf("unsigned package is rejected: %v", err)
+	}
+	if err := verify(nil, true); err == nil || !strings.Contains(err.Error(), "is not signed") {
+		t.Errorf("expected unsigned package error, got %v", err)
+	}
+
+	sign(key)
+	if err := verify([]ed25519.PublicKey{untrusted, trusted}, true); err != nil {
+		t.Errorf("signed package is rejected: %v", err)
+	}
+	if err := verify([]ed25519.PublicKey{untrusted}, false); err == nil ||
+		!strings.Contains(err.Error(), "not signed with a trusted key") {
+		t.Errorf("expected untrusted key error, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte("changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := verify([]ed25519.PublicKey{trusted}, true); err != nil {
+		t.Errorf("change of not hashed file is rejected: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "src", "Lib.nar"), []byte("module Lib\n\ndef x = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := verify([]ed25519.PublicKey{trusted}, true); err == nil ||
+		!strings.Contains(err.Error(), "not signed with a trusted key") {
+		t.Errorf("expected tampered package error, got %v", err)
+	}
+
+	sign(otherKey)
+	if err := verify([]ed25519.PublicKey{trusted}, true); err == nil {
+		t.Errorf("package signed with untrusted key is accepted")
+	}
+}
+
+func TestVerifyingProvider(t *testing.T) {
+	trusted, key := newTestKey(t)
+	root := writeTestPackage(t, map[string]string{
+		"nar.json":    `{"name": "lib", "version": "1.0.0"}`,
+		"src/Lib.nar": "module Lib\n",
+	})
+
+	provider := NewVerifyingProvider(NewFileSystemPackageProvider(root), []ed25519.PublicKey{trusted}, true)
+	if _, _, err := provider.LoadPackage("lib"); err == nil {
+		t.Errorf("unsigned package is loaded")
+	}
+
+	signature, err := SignPackage(loadTestPackage(t, root), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, signatureFileName), []byte(signature), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	provider = NewVerifyingProvider(NewFileSystemPackageProvider(root), []ed25519.PublicKey{trusted}, true)
+	if _, ok, err := provider.LoadPackage("lib"); err != nil || !ok {
+		t.Errorf("signed package is not loaded: %v", err)
+	}
+}
+
+func TestContentHashIsCached(t *testing.T) {
+	root := writeTestPackage(t, map[string]string{
+		"nar.json":    `{"name": "lib", "version": "1.0.0"}`,
+		"src/Lib.nar": "module Lib\n",
+	})
+	pkg := loadTestPackage(t, root)
+	hash, err := pkg.ContentHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(root); err != nil {
+		t.Fatal(err)
+	}
+	if cached, err := pkg.ContentHash(); err != nil || cached != hash {
+		t.Errorf("expected cached hash %s, got %s, %v", hash, cached, err)
+	}
+}
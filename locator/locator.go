@@ -3,7 +3,6 @@ package locator
 import (
 	"fmt"
 	"github.com/nar-lang/nar-compiler/bytecode"
-	"path/filepath"
 	"slices"
 	"strings"
 )
@@ -12,15 +11,25 @@ func NewLocator(provider ...Provider) Locator {
 	return &locator{providers: provider}
 }
 
+// NewLockedLocator creates locator that prefers versions pinned in the lock file and updates it after resolution.
+// In frozen mode lock file is not written and locator fails if resolved dependencies differ from the locked ones.
+func NewLockedLocator(lockFilePath string, frozen bool, provider ...Provider) Locator {
+	return &locator{providers: provider, lockFilePath: lockFilePath, frozen: frozen}
+}
+
 type Locator interface {
 	Packages() ([]Package, error)
 	FindPackage(name string) (Package, bool, error)
 	EntryPoint() (bytecode.FullIdentifier, error)
+	Graph() (*ResolutionGraph, error)
 }
 
 type locator struct {
-	providers []Provider
-	packages  map[string]Package
+	providers    []Provider
+	packages     map[string]Package
+	graph        *ResolutionGraph
+	lockFilePath string
+	frozen       bool
 }
 
 func (l *locator) EntryPoint() (bytecode.FullIdentifier, error) {
@@ -58,72 +67,100 @@ func (l *locator) Packages() ([]Package, error) {
 }
 
 func (l *locator) load() error {
-	l.packages = map[string]Package{}
+	if l.packages != nil {
+		return nil
+	}
 
-	var addPackage func(pkg Package) error
-	addPackage = func(pkg Package) error {
-		if addedPackage, ok := l.packages[pkg.Info().Name]; ok {
-			if addedPackage.Info().Version >= pkg.Info().Version {
-				return nil
-			}
+	var roots []Package
+	for _, provider := range l.providers {
+		exported, err := provider.ExportedPackages()
+		if err != nil {
+			return err
 		}
-		l.packages[pkg.Info().Name] = pkg
-		resolvedInfo := pkg.Info()
-		resolvedInfo.Dependencies = map[string]int{}
-		for depName, depVersion := range pkg.Info().Dependencies {
-			var depPkg Package
-			if depName == ".." || strings.HasPrefix(depName, "./") || strings.HasPrefix(depName, "../") {
-				path := pkg.Path()
-				if path != "" {
-					exp, err := NewFileSystemPackageProvider(filepath.Join(path, depName)).ExportedPackages()
-					if err != nil {
-						return err
-					}
-					if len(exp) > 0 {
-						depPkg = exp[0]
-					}
-				}
-			} else {
-				var ok bool
-				var err error
-				depPkg, ok, err = l.findDep(depName, depVersion)
-				if err != nil {
-					return err
-				}
-				if !ok {
-					depPkg = nil
-				}
+		for _, pkg := range exported {
+			i := slices.IndexFunc(roots, func(root Package) bool { return root.Info().Name == pkg.Info().Name })
+			if i < 0 {
+				roots = append(roots, pkg)
+			} else if roots[i].Info().Version.Compare(pkg.Info().Version) < 0 {
+				roots[i] = pkg
 			}
+		}
+	}
 
-			if depPkg == nil {
-				return fmt.Errorf(
-					"package `%s` with version %d not found (dependency of %s)",
-					depName, depVersion, pkg.Info().Name)
-			}
+	var locked *LockFile
+	if l.lockFilePath != "" {
+		var err error
+		locked, err = ReadLockFile(l.lockFilePath)
+		if err != nil {
+			return err
+		}
+		if locked == nil && l.frozen {
+			return fmt.Errorf("lock file %s not found", l.lockFilePath)
+		}
+	}
 
-			if err := addPackage(depPkg); err != nil {
+	r := newResolver(l.providers, roots, locked)
+	packages, err := r.resolve()
+	if err != nil {
+		return err
+	}
+	for name, pkg := range packages {
+		if f, ok := pkg.(fetcher); ok {
+			if packages[name], err = f.fetch(); err != nil {
 				return err
 			}
-			resolvedInfo.Dependencies[depPkg.Info().Name] = depPkg.Info().Version
 		}
-		pkg.SetInfo(resolvedInfo)
-		return nil
+		//packages cache content hash, so lock file and compiler do not hash files again
+		if _, err := packages[name].ContentHash(); err != nil {
+			return err
+		}
 	}
 
-	for _, provider := range l.providers {
-		exported, err := provider.ExportedPackages()
+	if l.lockFilePath != "" {
+		var deps []Package
+		for name, pkg := range packages {
+			if !r.isFixed(name) {
+				deps = append(deps, pkg)
+			}
+		}
+		resolved, err := newLockFile(deps)
 		if err != nil {
 			return err
 		}
-		for _, pkg := range exported {
-			if err := addPackage(pkg); err != nil {
+		if locked == nil {
+			if err := resolved.Write(l.lockFilePath); err != nil {
+				return err
+			}
+		} else if diffs := resolved.diff(locked); len(diffs) > 0 {
+			if l.frozen {
+				return fmt.Errorf("resolved dependencies differ from %s:\n\t%s", l.lockFilePath, strings.Join(diffs, "\n\t"))
+			}
+			if err := resolved.Write(l.lockFilePath); err != nil {
 				return err
 			}
 		}
 	}
+
+	l.graph = newResolutionGraph(r, packages)
+	for _, node := range l.graph.packages {
+		resolvedInfo := node.Package.Info()
+		resolvedInfo.Dependencies = map[string]Constraint{}
+		for _, dep := range node.Dependencies {
+			resolvedInfo.Dependencies[dep.Package.Package.Info().Name] = ExactConstraint(dep.Package.Package.Info().Version)
+		}
+		node.Package.SetInfo(resolvedInfo)
+	}
+	l.packages = packages
 	return nil
 }
 
+func (l *locator) Graph() (*ResolutionGraph, error) {
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l.graph, nil
+}
+
 func (l *locator) FindPackage(name string) (Package, bool, error) {
 	if err := l.load(); err != nil {
 		return nil, false, err
@@ -131,18 +168,3 @@ func (l *locator) FindPackage(name string) (Package, bool, error) {
 	pkg, ok := l.packages[name]
 	return pkg, ok, nil
 }
-
-func (l *locator) findDep(depName string, depVersion int) (Package, bool, error) {
-	for _, provider := range l.providers {
-		pkg, ok, err := provider.LoadPackage(depName)
-		if err != nil {
-			return nil, false, err
-		}
-		if ok {
-			if depVersion <= pkg.Info().Version {
-				return pkg, true, nil
-			}
-		}
-	}
-	return nil, false, nil
-}
@@ -0,0 +1,99 @@
+package locator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+const LockFileName = "nar.lock"
+
+// LockFile pins exact versions and source hashes of resolved dependencies
+type LockFile struct {
+	Packages []LockedPackage `json:"packages"`
+}
+
+type LockedPackage struct {
+	Name    string  `json:"name"`
+	Version Version `json:"version"`
+	Hash    string  `json:"hash"`
+}
+
+// ReadLockFile reads lock file, returns nil lock if file does not exist
+func ReadLockFile(path string) (*LockFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var lock LockFile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+	return &lock, nil
+}
+
+func (lock *LockFile) Write(path string) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func (lock *LockFile) find(name string) (LockedPackage, bool) {
+	for _, p := range lock.Packages {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return LockedPackage{}, false
+}
+
+// diff returns descriptions of differences between lock files
+func (lock *LockFile) diff(other *LockFile) (diffs []string) {
+	for _, p := range lock.Packages {
+		o, ok := other.find(p.Name)
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("`%s` %s is not locked", p.Name, p.Version))
+		case o.Version.Compare(p.Version) != 0:
+			diffs = append(diffs, fmt.Sprintf("`%s` is locked at %s but resolved to %s", p.Name, o.Version, p.Version))
+		case o.Hash != p.Hash:
+			diffs = append(diffs, fmt.Sprintf("`%s` %s content hash does not match the locked one", p.Name, p.Version))
+		}
+	}
+	for _, o := range other.Packages {
+		if _, ok := lock.find(o.Name); !ok {
+			diffs = append(diffs, fmt.Sprintf("`%s` is locked but not required", o.Name))
+		}
+	}
+	return
+}
+
+func newLockFile(packages []Package) (*LockFile, error) {
+	lock := &LockFile{}
+	for _, pkg := range packages {
+		hash, err := pkg.ContentHash()
+		if err != nil {
+			return nil, err
+		}
+		lock.Packages = append(lock.Packages, LockedPackage{
+			Name:    pkg.Info().Name,
+			Version: pkg.Info().Version,
+			Hash:    hash,
+		})
+	}
+	slices.SortFunc(lock.Packages, func(a, b LockedPackage) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return lock, nil
+}
@@ -0,0 +1,109 @@
+package locator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestLockedLocator(t *testing.T, lockPath string, frozen bool, root string) Locator {
+	t.Helper()
+	provider := newTestVersionsProvider(t, "a@1.0.0", "a@1.2.0", "b@1.0.0: a@^1.0.0")
+	return NewLockedLocator(lockPath, frozen, NewMemoryPackageProvider(parseTestPackage(t, root), nil), provider)
+}
+
+func resolvedVersions(t *testing.T, lc Locator) map[string]string {
+	t.Helper()
+	packages, err := lc.Packages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := map[string]string{}
+	for _, pkg := range packages {
+		versions[pkg.Info().Name] = pkg.Info().Version.String()
+	}
+	return versions
+}
+
+func TestLockFileIsWritten(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), LockFileName)
+	versions := resolvedVersions(t, newTestLockedLocator(t, lockPath, false, "root@1.0.0: b@^1.0.0"))
+	if versions["a"] != "1.2.0" || versions["b"] != "1.0.0" {
+		t.Errorf("unexpected versions %v", versions)
+	}
+
+	lock, err := ReadLockFile(lockPath)
+	if err != nil || lock == nil {
+		t.Fatalf("lock file is not written: %v", err)
+	}
+	if len(lock.Packages) != 2 || lock.Packages[0].Name != "a" || lock.Packages[1].Name != "b" {
+		t.Fatalf("unexpected locked packages %v", lock.Packages)
+	}
+	if lock.Packages[0].Version.String() != "1.2.0" || !strings.HasPrefix(lock.Packages[0].Hash, "sha256:") {
+		t.Errorf("unexpected locked package %v", lock.Packages[0])
+	}
+
+	//frozen locator accepts lock file that matches resolution
+	if _, err := newTestLockedLocator(t, lockPath, true, "root@1.0.0: b@^1.0.0").Packages(); err != nil {
+		t.Errorf("frozen locator rejects up to date lock file: %v", err)
+	}
+}
+
+func TestLockedVersionIsPreferred(t *testing.T) {
+	lockPath := filepath.Join(t.TempDir(), LockFileName)
+	lock := &LockFile{Packages: []LockedPackage{{Name: "a", Version: Version{Major: 1}, Hash: hashFiles(nil)}}}
+	if err := lock.Write(lockPath); err != nil {
+		t.Fatal(err)
+	}
+	versions := resolvedVersions(t, newTestLockedLocator(t, lockPath, true, "root@1.0.0: a@^1.0.0"))
+	if versions["a"] != "1.0.0" {
+		t.Errorf("expected locked version 1.0.0, got %s", versions["a"])
+	}
+}
+
+func TestFrozenLockFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		lock   *LockFile
+		root   string
+		err    string
+		update bool
+	}{
+		{"missing", nil, "root@1.0.0: a@^1.0.0", "not found", false},
+		{"stale version", &LockFile{Packages: []LockedPackage{{Name: "a", Version: Version{Major: 1}, Hash: hashFiles(nil)}}},
+			"root@1.0.0: a@^1.2.0", "`a` is locked at 1.0.0 but resolved to 1.2.0", true},
+		{"not required", &LockFile{Packages: []LockedPackage{
+			{Name: "a", Version: Version{Major: 1, Minor: 2}, Hash: hashFiles(nil)},
+			{Name: "b", Version: Version{Major: 1}, Hash: hashFiles(nil)}}},
+			"root@1.0.0: a@^1.0.0", "`b` is locked but not required", true},
+		{"hash mismatch", &LockFile{Packages: []LockedPackage{{Name: "a", Version: Version{Major: 1, Minor: 2}, Hash: "sha256:00"}}},
+			"root@1.0.0: a@^1.0.0", "`a` 1.2.0 content hash does not match the locked one", true},
+	}
+	for _, tt := range tests {
+		lockPath := filepath.Join(t.TempDir(), LockFileName)
+		if tt.lock != nil {
+			if err := tt.lock.Write(lockPath); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, err := newTestLockedLocator(t, lockPath, true, tt.root).Packages()
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: expected error `%s`, got %v", tt.name, tt.err, err)
+		}
+		if _, statErr := os.Stat(lockPath); tt.lock == nil && statErr == nil {
+			t.Errorf("%s: frozen locator should not write lock file", tt.name)
+		}
+		if !tt.update {
+			continue
+		}
+
+		//not frozen locator updates stale lock file
+		if _, err := newTestLockedLocator(t, lockPath, false, tt.root).Packages(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		if _, err := newTestLockedLocator(t, lockPath, true, tt.root).Packages(); err != nil {
+			t.Errorf("%s: lock file is not updated: %v", tt.name, err)
+		}
+	}
+}
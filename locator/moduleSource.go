@@ -1,25 +1,142 @@
 package locator
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/nar-lang/nar-compiler/ast"
+	"github.com/nar-lang/nar-compiler/common"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
 type ModuleSource interface {
 	FilePath() string
-	Content() []rune
+	// Content returns source text, file sources are read on the first call
+	Content() ([]rune, error)
+	// ModTime returns modification time of the file when it was listed or reloaded, zero for in-memory sources
+	ModTime() time.Time
+	// Hash returns sha256 hash of the content
+	Hash() (string, error)
+	// Reload drops loaded content if the file was modified and returns true in that case
+	Reload() (bool, error)
+	// Warnings returns problems found while loading content, e.g. invalid UTF-8 encoding
+	Warnings() []error
 }
 
 func NewModuleSource(filePath string, content []rune) ModuleSource {
-	return moduleSource{filePath, content}
+	return &moduleSource{filePath: filePath, content: content, loaded: true}
+}
+
+// NewFileModuleSource creates source that reads file lazily
+func NewFileModuleSource(filePath string) (ModuleSource, error) {
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return &moduleSource{filePath: filePath, modTime: stat.ModTime(), fromFile: true}, nil
+}
+
+// newDecodedModuleSource creates in-memory source from file bytes
+func newDecodedModuleSource(filePath string, data []byte) ModuleSource {
+	m := &moduleSource{filePath: filePath}
+	m.decode(data)
+	return m
 }
 
+// moduleSource is safe for concurrent use, content is loaded and reloaded under the mutex
 type moduleSource struct {
+	mu       sync.Mutex
 	filePath string
 	content  []rune
+	hash     string
+	modTime  time.Time
+	warnings []error
+	loaded   bool
+	fromFile bool
+}
+
+func (m *moduleSource) FilePath() string {
+	return m.filePath
+}
+
+func (m *moduleSource) Content() ([]rune, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.load(); err != nil {
+		return nil, err
+	}
+	return m.content, nil
+}
+
+func (m *moduleSource) ModTime() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.modTime
+}
+
+func (m *moduleSource) Hash() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.load(); err != nil {
+		return "", err
+	}
+	if m.hash == "" {
+		m.hash = hashBytes([]byte(string(m.content)))
+	}
+	return m.hash, nil
+}
+
+func (m *moduleSource) Reload() (bool, error) {
+	if !m.fromFile {
+		return false, nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stat, err := os.Stat(m.filePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to reload %s: %w", m.filePath, err)
+	}
+	if stat.ModTime().Equal(m.modTime) {
+		return false, nil
+	}
+	m.modTime = stat.ModTime()
+	m.content, m.hash, m.warnings, m.loaded = nil, "", nil, false
+	return true, nil
+}
+
+func (m *moduleSource) Warnings() []error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.warnings
+}
+
+func (m *moduleSource) load() error {
+	if m.loaded {
+		return nil
+	}
+	data, err := os.ReadFile(m.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", m.filePath, err)
+	}
+	m.decode(data)
+	return nil
 }
 
-func (m moduleSource) FilePath() string {
-	//TODO implement me
-	panic("implement me")
+func (m *moduleSource) decode(data []byte) {
+	m.content = []rune(string(data))
+	m.hash = hashBytes(data)
+	m.warnings = nil
+	if !utf8.Valid(data) {
+		m.warnings = append(m.warnings,
+			common.NewErrorAt(ast.NewLocationCursor(m.filePath, m.content, 0), "file is not valid UTF-8 text"))
+	}
+	m.loaded = true
 }
 
-func (m moduleSource) Content() []rune {
-	//TODO implement me
-	panic("implement me")
+func hashBytes(data []byte) string {
+	h := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(h[:])
 }
@@ -0,0 +1,100 @@
+package locator
+
+import (
+	"errors"
+	"github.com/nar-lang/nar-compiler/common"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func expectUTF8Warning(t *testing.T, source ModuleSource) {
+	t.Helper()
+	if _, err := source.Content(); err != nil {
+		t.Fatal(err)
+	}
+	warnings := source.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", warnings)
+	}
+	var located common.ErrorWithLocation
+	if !errors.As(warnings[0], &located) {
+		t.Fatalf("expected warning with location, got %v", warnings[0])
+	}
+	if line, column, _, _ := located.Location().GetLineAndColumn(); line != 1 || column != 1 {
+		t.Errorf("expected warning at 1:1, got %d:%d", line, column)
+	}
+	if located.Location().FilePath() != source.FilePath() {
+		t.Errorf("expected warning in %s, got %s", source.FilePath(), located.Location().FilePath())
+	}
+	if !strings.Contains(located.Message(), "not valid UTF-8") {
+		t.Errorf("unexpected warning: %s", located.Message())
+	}
+}
+
+func TestInvalidUTF8Warning(t *testing.T) {
+	content := "module Test\n\ndef x = \"\xff\"\n"
+	root := writeTestPackage(t, map[string]string{
+		"nar.json":     `{"name": "test", "version": "1.0.0"}`,
+		"src/Test.nar": content,
+		"src/Good.nar": "module Good\n",
+	})
+	pkg := loadTestPackage(t, root)
+	for _, source := range pkg.Sources() {
+		if filepath.Base(source.FilePath()) == "Good.nar" {
+			if _, err := source.Content(); err != nil {
+				t.Fatal(err)
+			}
+			if len(source.Warnings()) != 0 {
+				t.Errorf("unexpected warnings %v", source.Warnings())
+			}
+		} else {
+			expectUTF8Warning(t, source)
+		}
+	}
+
+	archivePath := writeTestZip(t, map[string]string{
+		"nar.json":     `{"name": "test", "version": "1.0.0"}`,
+		"src/Test.nar": content,
+	})
+	archived, ok, err := NewArchivePackageProvider(archivePath).LoadPackage("test")
+	if err != nil || !ok {
+		t.Fatalf("package is not loaded: %v", err)
+	}
+	expectUTF8Warning(t, archived.Sources()[0])
+}
+
+func TestSourceLoadErrors(t *testing.T) {
+	if _, err := NewFileSystemPackageProvider(t.TempDir()).ExportedPackages(); err == nil ||
+		!strings.Contains(err.Error(), "nar.json") {
+		t.Errorf("expected missing nar.json error, got %v", err)
+	}
+
+	root := writeTestPackage(t, map[string]string{
+		"nar.json": `{"name": "test", "version": "1.0.0"}`,
+	})
+	if _, err := NewFileSystemPackageProvider(root).ExportedPackages(); err == nil ||
+		!strings.Contains(err.Error(), "failed to load sources") {
+		t.Errorf("expected missing src directory error, got %v", err)
+	}
+
+	if _, err := NewFileModuleSource(filepath.Join(root, "src", "Missing.nar")); err == nil {
+		t.Errorf("expected missing file error")
+	}
+
+	filePath := filepath.Join(root, "Test.nar")
+	if err := os.WriteFile(filePath, []byte("module Test\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	source, err := NewFileModuleSource(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filePath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := source.Content(); err == nil || !strings.Contains(err.Error(), "failed to read") {
+		t.Errorf("expected read error, got %v", err)
+	}
+}
@@ -3,25 +3,43 @@ package locator
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 )
 
 type Package interface {
 	Info() PackageInfo
 	SetInfo(info PackageInfo)
-	Sources() map[string][]rune
+	// Sources returns module sources ordered by file path
+	Sources() []ModuleSource
 	NativeFilePaths(platform string) ([]string, error)
 	Path() string
+	// ContentHash returns hash of nar.json, sources and native files of the package
+	ContentHash() (string, error)
+	// Signature returns ed25519 signature of the content hash or nil if package is not signed
+	Signature() ([]byte, error)
+}
+
+// fetcher is a package that is described by metadata and downloaded when its content is accessed.
+// Locator fetches selected versions after resolution to report download errors.
+type fetcher interface {
+	fetch() (Package, error)
 }
 
 type PackageInfo struct {
-	Name         string         `json:"name"`
-	Version      int            `json:"version"`
-	NarVersion   int            `json:"nar-version"`
-	Dependencies map[string]int `json:"dependencies"`
-	Main         string         `json:"main"`
+	Name         string                `json:"name"`
+	Version      Version               `json:"version"`
+	NarVersion   int                   `json:"nar-version"`
+	Dependencies map[string]Constraint `json:"dependencies"`
+	Main         string                `json:"main"`
+	Overrides    map[string]Constraint `json:"overrides,omitempty"`
 }
 
-func NewLoadedPackage(info PackageInfo, sources map[string][]rune, path string) Package {
+func NewLoadedPackage(info PackageInfo, sources []ModuleSource, path string) Package {
+	sources = slices.Clone(sources)
+	slices.SortFunc(sources, func(a, b ModuleSource) int {
+		return strings.Compare(a.FilePath(), b.FilePath())
+	})
 	return &loadedPackage{
 		info:    info,
 		sources: sources,
@@ -30,9 +48,10 @@ func NewLoadedPackage(info PackageInfo, sources map[string][]rune, path string)
 }
 
 type loadedPackage struct {
-	info    PackageInfo
-	sources map[string][]rune
-	path    string
+	info        PackageInfo
+	sources     []ModuleSource
+	path        string
+	contentHash string
 }
 
 func (l *loadedPackage) Info() PackageInfo {
@@ -43,7 +62,7 @@ func (l *loadedPackage) SetInfo(info PackageInfo) {
 	l.info = info
 }
 
-func (l *loadedPackage) Sources() map[string][]rune {
+func (l *loadedPackage) Sources() []ModuleSource {
 	return l.sources
 }
 
@@ -20,7 +20,7 @@ func NewFileSystemPackageProvider(path string) Provider {
 type fileSystemProvider struct {
 	path    string
 	pkgInfo *PackageInfo
-	pkgSrcs map[string][]rune
+	pkgSrcs []ModuleSource
 }
 
 func (f *fileSystemProvider) ExportedPackages() ([]Package, error) {
@@ -30,7 +30,7 @@ func (f *fileSystemProvider) ExportedPackages() ([]Package, error) {
 	if err := f.loadSources(); err != nil {
 		return nil, err
 	}
-	return []Package{NewLoadedPackage(*f.pkgInfo, f.pkgSrcs, f.path)}, nil
+	return []Package{f.newPackage()}, nil
 }
 
 func (f *fileSystemProvider) LoadPackage(name string) (Package, bool, error) {
@@ -41,7 +41,7 @@ func (f *fileSystemProvider) LoadPackage(name string) (Package, bool, error) {
 		if err := f.loadSources(); err != nil {
 			return nil, false, err
 		}
-		return NewLoadedPackage(*f.pkgInfo, f.pkgSrcs, f.path), true, nil
+		return f.newPackage(), true, nil
 	}
 	if strings.HasPrefix(name, ".") {
 		provider := NewFileSystemPackageProvider(filepath.Join(f.path, name))
@@ -56,6 +56,10 @@ func (f *fileSystemProvider) LoadPackage(name string) (Package, bool, error) {
 	return nil, false, nil
 }
 
+func (f *fileSystemProvider) newPackage() Package {
+	return NewLoadedPackage(*f.pkgInfo, f.pkgSrcs, f.path)
+}
+
 func (f *fileSystemProvider) containsPackage() bool {
 	_, err := os.Stat(filepath.Join(f.path, "nar.json"))
 	return err == nil
@@ -77,11 +81,12 @@ func (f *fileSystemProvider) loadInfo() error {
 	return nil
 }
 
+// loadSources lists source files of the package, their content is read lazily
 func (f *fileSystemProvider) loadSources() error {
 	if f.pkgSrcs == nil {
-		f.pkgSrcs = map[string][]rune{}
+		var sources []ModuleSource
 		root := filepath.Join(f.path, "src")
-		filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
 			if err != nil {
 				return err
 			}
@@ -91,20 +96,31 @@ func (f *fileSystemProvider) loadSources() error {
 			if filepath.Ext(path) != ".nar" {
 				return nil
 			}
-			content, err := os.ReadFile(path)
+			source, err := NewFileModuleSource(path)
 			if err != nil {
-				return fmt.Errorf("failed to read file: %w", err)
+				return err
 			}
-			f.pkgSrcs[path] = []rune(string(content))
+			sources = append(sources, source)
 			return nil
 		})
+		if err != nil {
+			return fmt.Errorf("failed to load sources of package at %s: %w", f.path, err)
+		}
+		f.pkgSrcs = sources
+		if f.pkgSrcs == nil {
+			f.pkgSrcs = []ModuleSource{}
+		}
 	}
 	return nil
 }
 
 func NewMemoryPackageProvider(info PackageInfo, sources map[string][]rune) Provider {
+	moduleSources := make([]ModuleSource, 0, len(sources))
+	for path, content := range sources {
+		moduleSources = append(moduleSources, NewModuleSource(path, content))
+	}
 	return &memoryProvider{
-		pkg: NewLoadedPackage(info, sources, ""),
+		pkg: NewLoadedPackage(info, moduleSources, ""),
 	}
 }
 
@@ -0,0 +1,175 @@
+package locator
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// VersionedProvider is a provider that can contain several versions of the same package
+type VersionedProvider interface {
+	Provider
+	// LoadPackageVersions returns all versions of the package
+	LoadPackageVersions(name string) ([]Package, error)
+	// LoadPackageInRange returns the highest version of the package that is allowed by the constraint
+	LoadPackageInRange(name string, constraint Constraint) (Package, bool, error)
+}
+
+// NewRegistryProvider creates provider of packages stored in `<root>/<name>/<version>/` directories
+func NewRegistryProvider(root string) VersionedProvider {
+	return &registryProvider{root: root, versions: map[string][]Package{}}
+}
+
+type registryProvider struct {
+	root     string
+	versions map[string][]Package
+}
+
+func (r *registryProvider) ExportedPackages() ([]Package, error) {
+	return nil, nil
+}
+
+func (r *registryProvider) LoadPackage(name string) (Package, bool, error) {
+	return r.LoadPackageInRange(name, Constraint{})
+}
+
+func (r *registryProvider) LoadPackageInRange(name string, constraint Constraint) (Package, bool, error) {
+	versions, err := r.LoadPackageVersions(name)
+	if err != nil {
+		return nil, false, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if constraint.Allows(versions[i].Info().Version) {
+			return versions[i], true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// LoadPackageVersions returns versions of the package sorted from the lowest to the highest
+func (r *registryProvider) LoadPackageVersions(name string) ([]Package, error) {
+	if versions, ok := r.versions[name]; ok {
+		return versions, nil
+	}
+	if !isValidPackageName(name) {
+		return nil, nil
+	}
+	dirs, err := os.ReadDir(filepath.Join(r.root, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			r.versions[name] = nil
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+	var versions []Package
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		version, err := ParseVersion(dir.Name())
+		if err != nil {
+			continue
+		}
+		provider := NewFileSystemPackageProvider(filepath.Join(r.root, name, dir.Name())).(*fileSystemProvider)
+		if !provider.containsPackage() {
+			continue
+		}
+		pkg, ok, err := provider.LoadPackage(name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("package at %s is not `%s`", provider.path, name)
+		}
+		if pkg.Info().Version.Compare(version) != 0 {
+			return nil, fmt.Errorf("package at %s has version %s", provider.path, pkg.Info().Version)
+		}
+		versions = append(versions, pkg)
+	}
+	slices.SortFunc(versions, func(a, b Package) int {
+		return a.Info().Version.Compare(b.Info().Version)
+	})
+	r.versions[name] = versions
+	return versions, nil
+}
+
+func isValidPackageName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\`)
+}
+
+// installPath returns directory of the package version in the registry and checks that it is inside the registry
+func installPath(root string, info PackageInfo) (string, error) {
+	if !isValidPackageName(info.Name) {
+		return "", fmt.Errorf("invalid package name `%s`", info.Name)
+	}
+	version := info.Version.String()
+	if !isValidPackageName(version) || strings.Contains(version, "..") {
+		return "", fmt.Errorf("invalid version `%s` of package `%s`", version, info.Name)
+	}
+	root = filepath.Clean(root)
+	target := filepath.Join(root, info.Name, version)
+	if rel, err := filepath.Rel(root, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("package `%s` %s is installed outside of %s", info.Name, version, root)
+	}
+	return target, nil
+}
+
+// InstallArchive extracts package archive to the registry and returns installed package info.
+// It fails if the same version of the package is already installed.
+func InstallArchive(root string, archivePath string) (PackageInfo, error) {
+	files, err := readArchive(archivePath)
+	if err != nil {
+		return PackageInfo{}, fmt.Errorf("failed to read archive %s: %w", archivePath, err)
+	}
+	pkg, err := newArchivePackage(archivePath, files)
+	if err != nil {
+		return PackageInfo{}, err
+	}
+	info := pkg.Info()
+	target, err := installPath(root, info)
+	if err != nil {
+		return PackageInfo{}, err
+	}
+	if _, err := os.Stat(target); err == nil {
+		return PackageInfo{}, fmt.Errorf("package `%s` %s is already installed", info.Name, info.Version)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return PackageInfo{}, fmt.Errorf("failed to create directory: %w", err)
+	}
+	tmp, err := os.MkdirTemp(filepath.Dir(target), ".install-")
+	if err != nil {
+		return PackageInfo{}, fmt.Errorf("failed to create directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	prefix, _ := archiveRoot(files)
+	for _, f := range files {
+		name := path.Clean(strings.TrimPrefix(f.name, "./"))
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		name = strings.TrimPrefix(name, prefix)
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return PackageInfo{}, fmt.Errorf("archive %s contains invalid path `%s`", archivePath, f.name)
+		}
+		filePath := filepath.Join(tmp, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return PackageInfo{}, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(filePath, f.content, f.mode.Perm()|0200); err != nil {
+			return PackageInfo{}, fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	if err := os.Chmod(tmp, 0755); err != nil {
+		return PackageInfo{}, fmt.Errorf("failed to install package: %w", err)
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		return PackageInfo{}, fmt.Errorf("failed to install package: %w", err)
+	}
+	return info, nil
+}
@@ -0,0 +1,56 @@
+package locator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInstallArchive(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "registry")
+	archivePath := writeTestZip(t, map[string]string{
+		"lib/nar.json":    `{"name": "lib", "version": "1.0.0-beta.1"}`,
+		"lib/src/Lib.nar": "module Lib\n",
+	})
+	info, err := InstallArchive(root, archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "lib", info.Version.String(), "src", "Lib.nar")); err != nil {
+		t.Errorf("package is not installed: %v", err)
+	}
+	if _, err := InstallArchive(root, archivePath); err == nil || !strings.Contains(err.Error(), "already installed") {
+		t.Errorf("expected already installed error, got %v", err)
+	}
+}
+
+func TestInstallHostileArchive(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "registry")
+	archivePath := writeTestZip(t, map[string]string{
+		"nar.json":    `{"name": "lib", "version": "1.0.0-x/../../../pwn"}`,
+		"src/Lib.nar": "module Lib\n",
+	})
+	if _, err := InstallArchive(root, archivePath); err == nil {
+		t.Errorf("hostile archive is installed")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwn")); err == nil {
+		t.Errorf("hostile archive is written outside of the registry")
+	}
+
+	for _, version := range []Version{
+		{Major: 1, Prerelease: "x/../../../pwn"},
+		{Major: 1, Prerelease: `x\..\..\..\pwn`},
+		{Major: 1, Prerelease: "x..y"},
+	} {
+		if _, err := installPath(root, PackageInfo{Name: "lib", Version: version}); err == nil {
+			t.Errorf("expected error for version `%s`", version)
+		}
+	}
+	for _, name := range []string{"..", "a/b", `a\b`, ""} {
+		if _, err := installPath(root, PackageInfo{Name: name, Version: Version{Major: 1}}); err == nil {
+			t.Errorf("expected error for name `%s`", name)
+		}
+	}
+}
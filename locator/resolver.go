@@ -0,0 +1,284 @@
+package locator
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const maxResolveIterations = 100
+
+type requirement struct {
+	constraint   Constraint
+	path         []string
+	overriddenBy string
+}
+
+func (r requirement) String() string {
+	s := fmt.Sprintf("%s required by %s", r.constraint, strings.Join(r.path, " -> "))
+	if r.overriddenBy != "" {
+		s += fmt.Sprintf(" (overridden by %s)", r.overriddenBy)
+	}
+	return s
+}
+
+// resolver selects versions of dependencies of root packages
+type resolver struct {
+	providers  []Provider
+	roots      []Package
+	fixed      map[string]Package
+	candidates map[string][]Package
+	locked     *LockFile
+	overrides  map[string]requirement
+	relatives  map[string]string
+}
+
+func newResolver(providers []Provider, roots []Package, locked *LockFile) *resolver {
+	r := &resolver{
+		providers:  providers,
+		roots:      roots,
+		fixed:      map[string]Package{},
+		candidates: map[string][]Package{},
+		locked:     locked,
+		overrides:  map[string]requirement{},
+		relatives:  map[string]string{},
+	}
+	for _, root := range roots {
+		r.fixed[root.Info().Name] = root
+		for name, constraint := range root.Info().Overrides {
+			r.overrides[name] = requirement{constraint: constraint, path: []string{root.Info().Name}}
+		}
+	}
+	return r
+}
+
+// resolve selects the highest version of every dependency that satisfies constraints of all its dependents.
+// Selection is repeated until it does not change, as newly selected versions can have other dependencies.
+func (r *resolver) resolve() (map[string]Package, error) {
+	selected := map[string]Package{}
+	for name, pkg := range r.fixed {
+		selected[name] = pkg
+	}
+
+	for iteration := 0; iteration < maxResolveIterations; iteration++ {
+		requirements := map[string][]requirement{}
+		visited := map[string]struct{}{}
+
+		var visit func(pkg Package, path []string) error
+		visit = func(pkg Package, path []string) error {
+			if slices.Contains(path, pkg.Info().Name) {
+				cycle := path[slices.Index(path, pkg.Info().Name):]
+				return fmt.Errorf("package dependency cycle: %s -> %s", strings.Join(cycle, " -> "), pkg.Info().Name)
+			}
+			if _, ok := visited[pkg.Info().Name]; ok {
+				return nil
+			}
+			visited[pkg.Info().Name] = struct{}{}
+			path = append(slices.Clip(path), pkg.Info().Name)
+
+			depNames := make([]string, 0, len(pkg.Info().Dependencies))
+			for depName := range pkg.Info().Dependencies {
+				depNames = append(depNames, depName)
+			}
+			slices.Sort(depNames)
+
+			for _, depName := range depNames {
+				constraint := pkg.Info().Dependencies[depName]
+				if isRelativeDependency(depName) {
+					dep, err := r.loadRelative(pkg, depName, constraint, path)
+					if err != nil {
+						return err
+					}
+					selected[dep.Info().Name] = dep
+					if err := visit(dep, path); err != nil {
+						return err
+					}
+					continue
+				}
+
+				req := requirement{constraint: constraint, path: path}
+				if override, ok := r.overrides[depName]; ok {
+					req = requirement{constraint: override.constraint, path: path, overriddenBy: override.path[0]}
+				}
+				requirements[depName] = append(requirements[depName], req)
+				dep, ok := selected[depName]
+				if !ok || !req.constraint.Allows(dep.Info().Version) {
+					var err error
+					dep, err = r.pickVersion(depName, requirements[depName])
+					if err != nil {
+						return err
+					}
+					selected[depName] = dep
+				}
+				if err := visit(dep, path); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		for _, root := range r.roots {
+			if err := visit(root, nil); err != nil {
+				return nil, err
+			}
+		}
+
+		changed := false
+		for name, reqs := range requirements {
+			best, err := r.pickVersion(name, reqs)
+			if err != nil {
+				return nil, err
+			}
+			if selected[name].Info().Version.Compare(best.Info().Version) != 0 {
+				selected[name] = best
+				changed = true
+			}
+		}
+		for name := range selected {
+			if _, ok := visited[name]; !ok {
+				delete(selected, name)
+			}
+		}
+		if !changed {
+			return selected, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to resolve package versions in %d iterations", maxResolveIterations)
+}
+
+// pickVersion returns the locked version of the package if it satisfies all requirements
+// or the highest version that does
+func (r *resolver) pickVersion(name string, reqs []requirement) (Package, error) {
+	candidates, err := r.loadCandidates(name)
+	if err != nil {
+		return nil, err
+	}
+	var lockedVersion *Version
+	if r.locked != nil {
+		if p, ok := r.locked.find(name); ok {
+			lockedVersion = &p.Version
+		}
+	}
+	var best Package
+	for _, c := range candidates {
+		ok := true
+		for _, req := range reqs {
+			if !req.constraint.Allows(c.Info().Version) {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		if lockedVersion != nil && c.Info().Version.Compare(*lockedVersion) == 0 {
+			return c, nil
+		}
+		if best == nil || c.Info().Version.Compare(best.Info().Version) > 0 {
+			best = c
+		}
+	}
+	if best == nil {
+		return nil, newResolveError(name, reqs, candidates)
+	}
+	return best, nil
+}
+
+// isFixed tells if package is a root or a relative path dependency that has no version choice
+func (r *resolver) isFixed(name string) bool {
+	_, ok := r.fixed[name]
+	return ok
+}
+
+func (r *resolver) loadCandidates(name string) ([]Package, error) {
+	if pkg, ok := r.fixed[name]; ok {
+		return []Package{pkg}, nil
+	}
+	if candidates, ok := r.candidates[name]; ok {
+		return candidates, nil
+	}
+	var candidates []Package
+	for _, provider := range r.providers {
+		if versioned, ok := provider.(VersionedProvider); ok {
+			versions, err := versioned.LoadPackageVersions(name)
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, versions...)
+			continue
+		}
+		pkg, ok, err := provider.LoadPackage(name)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			candidates = append(candidates, pkg)
+		}
+	}
+	r.candidates[name] = candidates
+	return candidates, nil
+}
+
+// relativeName returns name of the package located by relative path dependency of another package
+func (r *resolver) relativeName(pkg Package, depName string) string {
+	return r.relatives[filepath.Join(pkg.Path(), depName)]
+}
+
+func (r *resolver) loadRelative(pkg Package, depName string, constraint Constraint, path []string) (Package, error) {
+	if pkg.Path() != "" {
+		depPath := filepath.Join(pkg.Path(), depName)
+		provider := NewFileSystemPackageProvider(depPath)
+		if isArchive(depPath) {
+			provider = NewArchivePackageProvider(depPath)
+		}
+		exp, err := provider.ExportedPackages()
+		if err != nil {
+			return nil, err
+		}
+		if len(exp) > 0 {
+			dep := exp[0]
+			if fixed, ok := r.fixed[dep.Info().Name]; ok {
+				dep = fixed
+			} else {
+				r.fixed[dep.Info().Name] = dep
+			}
+			r.relatives[depPath] = dep.Info().Name
+			if !constraint.Allows(dep.Info().Version) {
+				return nil, newResolveError(depName, []requirement{{constraint: constraint, path: path}}, exp)
+			}
+			return dep, nil
+		}
+	}
+	return nil, newResolveError(depName, []requirement{{constraint: constraint, path: path}}, nil)
+}
+
+func isRelativeDependency(name string) bool {
+	return name == ".." || strings.HasPrefix(name, "./") || strings.HasPrefix(name, "../")
+}
+
+func newResolveError(name string, reqs []requirement, candidates []Package) error {
+	sb := strings.Builder{}
+	sb.WriteString(fmt.Sprintf("cannot resolve version of package `%s`:", name))
+	for _, req := range reqs {
+		sb.WriteString("\n\t")
+		sb.WriteString(req.String())
+	}
+	if len(candidates) == 0 {
+		sb.WriteString("\nno versions found")
+	} else {
+		versions := make([]Version, len(candidates))
+		for i, c := range candidates {
+			versions[i] = c.Info().Version
+		}
+		slices.SortFunc(versions, Version.Compare)
+		sb.WriteString("\navailable versions: ")
+		for i, v := range versions {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(v.String())
+		}
+	}
+	return fmt.Errorf("%s", sb.String())
+}
@@ -0,0 +1,115 @@
+package locator
+
+import (
+	"strings"
+	"testing"
+)
+
+// testVersionsProvider provides versions of packages described as `name@version: dep@constraint, ...`
+type testVersionsProvider map[string][]Package
+
+func newTestVersionsProvider(t *testing.T, packages ...string) testVersionsProvider {
+	p := testVersionsProvider{}
+	for _, s := range packages {
+		info := parseTestPackage(t, s)
+		p[info.Name] = append(p[info.Name], NewLoadedPackage(info, nil, ""))
+	}
+	return p
+}
+
+func parseTestPackage(t *testing.T, s string) PackageInfo {
+	head, deps, _ := strings.Cut(s, ":")
+	name, version, _ := strings.Cut(strings.TrimSpace(head), "@")
+	v, err := ParseVersion(version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := PackageInfo{Name: name, Version: v, Dependencies: map[string]Constraint{}}
+	for _, dep := range strings.Split(deps, ",") {
+		if dep = strings.TrimSpace(dep); dep == "" {
+			continue
+		}
+		depName, constraint, _ := strings.Cut(dep, "@")
+		c, err := ParseConstraint(constraint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		info.Dependencies[depName] = c
+	}
+	return info
+}
+
+func (p testVersionsProvider) ExportedPackages() ([]Package, error) {
+	return nil, nil
+}
+
+func (p testVersionsProvider) LoadPackage(name string) (Package, bool, error) {
+	return p.LoadPackageInRange(name, Constraint{})
+}
+
+func (p testVersionsProvider) LoadPackageVersions(name string) ([]Package, error) {
+	return p[name], nil
+}
+
+func (p testVersionsProvider) LoadPackageInRange(name string, constraint Constraint) (Package, bool, error) {
+	versions := p[name]
+	for i := len(versions) - 1; i >= 0; i-- {
+		if constraint.Allows(versions[i].Info().Version) {
+			return versions[i], true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func TestResolver(t *testing.T) {
+	available := []string{
+		"a@1.0.0", "a@1.2.0", "a@1.3.0-beta.1", "a@2.0.0",
+		"b@1.0.0: a@^1.0.0", "b@1.1.0: a@~1.2.0",
+		"c@0.1.0: a@>=2", "c@0.1.1: a@2.0.0",
+		"d@1.0.0-rc.2", "d@1.0.0-rc.10",
+		"x@1.0.0: y@^1.0.0", "y@1.0.0: z@^1.0.0", "z@1.0.0: x@^1.0.0",
+	}
+	tests := []struct {
+		root     string
+		expected map[string]string
+		err      string
+	}{
+		{"root@1.0.0: a@^1.0.0", map[string]string{"a": "1.2.0"}, ""},
+		{"root@1.0.0: a@*", map[string]string{"a": "2.0.0"}, ""},
+		{"root@1.0.0: a@^1.3.0-beta.1", map[string]string{"a": "1.3.0-beta.1"}, ""},
+		{"root@1.0.0: b@^1.0.0", map[string]string{"a": "1.2.0", "b": "1.1.0"}, ""},
+		{"root@1.0.0: a@<1.2.0, b@1.0.0", map[string]string{"a": "1.0.0", "b": "1.0.0"}, ""},
+		{"root@1.0.0: d@^1.0.0-rc.1", map[string]string{"d": "1.0.0-rc.10"}, ""},
+		{"root@1.0.0: c@~0.1.0", map[string]string{"a": "2.0.0", "c": "0.1.1"}, ""},
+		{"root@1.0.0: a@^3.0.0", nil, "available versions: 1.0.0, 1.2.0, 1.3.0-beta.1, 2.0.0"},
+		{"root@1.0.0: b@1.1.0, c@0.1.1", nil, "cannot resolve version of package `a`"},
+		{"root@1.0.0: e@^1.0.0", nil, "no versions found"},
+		{"root@1.0.0: a@^1.0.0, y@^1.0.0", nil, "package dependency cycle: y -> z -> x -> y"},
+	}
+	for _, tt := range tests {
+		root := NewLoadedPackage(parseTestPackage(t, tt.root), nil, "")
+		r := newResolver([]Provider{newTestVersionsProvider(t, available...)}, []Package{root}, nil)
+		packages, err := r.resolve()
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expected error `%s`, got %v", tt.root, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.root, err)
+			continue
+		}
+		if len(packages) != len(tt.expected)+1 {
+			t.Errorf("%s: expected %d packages, got %d", tt.root, len(tt.expected)+1, len(packages))
+		}
+		for name, version := range tt.expected {
+			pkg, ok := packages[name]
+			if !ok {
+				t.Errorf("%s: package `%s` is not resolved", tt.root, name)
+			} else if got := pkg.Info().Version.String(); got != version {
+				t.Errorf("%s: expected %s %s, got %s", tt.root, name, version, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package locator
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version of a package, i.e. `1.2.3` or `1.0.0-beta.1`.
+// Build metadata is ignored.
+type Version struct {
+	Major, Minor, Patch int
+	Prerelease          string
+}
+
+func ParseVersion(s string) (Version, error) {
+	v, parts, err := parsePartialVersion(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if parts != 3 {
+		return Version{}, fmt.Errorf("invalid version `%s`: expected major.minor.patch", s)
+	}
+	return v, nil
+}
+
+// parsePartialVersion parses version with optional minor and patch parts, `x` and `*` are treated as omitted ones.
+// Returns number of given parts.
+func parsePartialVersion(s string) (Version, int, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+	var v Version
+	if i := strings.Index(s, "-"); i >= 0 {
+		v.Prerelease = s[i+1:]
+		s = s[:i]
+		if v.Prerelease == "" {
+			return Version{}, 0, fmt.Errorf("invalid version `%s`: empty prerelease", s)
+		}
+		for _, id := range strings.Split(v.Prerelease, ".") {
+			if !isValidPrereleaseIdentifier(id) {
+				return Version{}, 0, fmt.Errorf("invalid version `%s`: invalid prerelease `%s`", s, v.Prerelease)
+			}
+		}
+	}
+	fields := strings.Split(s, ".")
+	if len(fields) > 3 {
+		return Version{}, 0, fmt.Errorf("invalid version `%s`", s)
+	}
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	parts := 0
+	for i, f := range fields {
+		if f == "x" || f == "X" || f == "*" {
+			break
+		}
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return Version{}, 0, fmt.Errorf("invalid version `%s`", s)
+		}
+		*nums[i] = n
+		parts++
+	}
+	return v, parts, nil
+}
+
+// isValidPrereleaseIdentifier tells if dot separated prerelease identifier is not empty and consists of [0-9A-Za-z-]
+func isValidPrereleaseIdentifier(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 if v is lower, equal or greater than o. Prerelease versions are lower than releases.
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch} {
+		if d < 0 {
+			return -1
+		}
+		if d > 0 {
+			return 1
+		}
+	}
+	switch {
+	case v.Prerelease == o.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case o.Prerelease == "":
+		return -1
+	default:
+		return comparePrerelease(v.Prerelease, o.Prerelease)
+	}
+}
+
+// comparePrerelease compares dot separated identifiers one by one. Numeric identifiers are compared
+// as numbers and are lower than alphanumeric ones. Shorter prerelease is lower if all its identifiers are equal.
+func comparePrerelease(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if d := cmp.Compare(an, bn); d != 0 {
+				return d
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if d := strings.Compare(as[i], bs[i]); d != 0 {
+				return d
+			}
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
+}
+
+// samePatch tells if versions differ only by prerelease
+func (v Version) samePatch(o Version) bool {
+	return v.Major == o.Major && v.Minor == o.Minor && v.Patch == o.Patch
+}
+
+func (v Version) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.String())
+}
+
+// UnmarshalJSON reads version string. Integer version `n` of older package files is read as `n.0.0`
+func (v *Version) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		*v = Version{Major: n}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseVersion(s)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
+
+type comparator struct {
+	op      string
+	version Version
+}
+
+func (c comparator) allows(v Version) bool {
+	d := v.Compare(c.version)
+	switch c.op {
+	case ">":
+		return d > 0
+	case ">=":
+		return d >= 0
+	case "<":
+		return d < 0
+	case "<=":
+		return d <= 0
+	default:
+		return d == 0
+	}
+}
+
+// Constraint is a set of allowed versions, e.g. `^1.2.0`, `~1.2`, `>=2.0 <3`, `1.x || 2.1.0`.
+// Comparators separated with spaces should be satisfied all, alternatives are separated with `||`.
+// Zero constraint allows any version.
+type Constraint struct {
+	source string
+	sets   [][]comparator
+}
+
+func ParseConstraint(s string) (Constraint, error) {
+	c := Constraint{source: strings.TrimSpace(s)}
+	if c.source == "" {
+		return c, nil
+	}
+	for _, alt := range strings.Split(c.source, "||") {
+		var set []comparator
+		for _, field := range strings.Fields(alt) {
+			cmps, err := parseComparator(field)
+			if err != nil {
+				return Constraint{}, fmt.Errorf("invalid version constraint `%s`: %w", s, err)
+			}
+			set = append(set, cmps...)
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+// ExactConstraint returns constraint that allows only given version
+func ExactConstraint(v Version) Constraint {
+	return Constraint{source: v.String(), sets: [][]comparator{{{op: "=", version: v}}}}
+}
+
+func parseComparator(s string) ([]comparator, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(s, prefix) {
+			op = prefix
+			s = s[len(prefix):]
+			break
+		}
+	}
+	if s == "*" || s == "x" || s == "X" {
+		return nil, nil
+	}
+	v, parts, err := parsePartialVersion(s)
+	if err != nil {
+		return nil, err
+	}
+	if parts == 0 {
+		return nil, nil
+	}
+
+	//upper bound of the versions range that starts with given parts
+	next := func(parts int) Version {
+		switch parts {
+		case 1:
+			return Version{Major: v.Major + 1}
+		case 2:
+			return Version{Major: v.Major, Minor: v.Minor + 1}
+		default:
+			return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+		}
+	}
+	lower := comparator{op: ">=", version: v}
+
+	switch op {
+	case "^":
+		switch {
+		case v.Major > 0 || parts == 1:
+			return []comparator{lower, {op: "<", version: next(1)}}, nil
+		case v.Minor > 0 || parts == 2:
+			return []comparator{lower, {op: "<", version: next(2)}}, nil
+		default:
+			return []comparator{lower, {op: "<", version: next(3)}}, nil
+		}
+	case "~":
+		if parts == 1 {
+			return []comparator{lower, {op: "<", version: next(1)}}, nil
+		}
+		return []comparator{lower, {op: "<", version: next(2)}}, nil
+	case "", "=":
+		if parts == 3 {
+			return []comparator{{op: "=", version: v}}, nil
+		}
+		return []comparator{lower, {op: "<", version: next(parts)}}, nil
+	case ">":
+		if parts < 3 {
+			return []comparator{{op: ">=", version: next(parts)}}, nil
+		}
+		return []comparator{{op: ">", version: v}}, nil
+	case "<=":
+		if parts < 3 {
+			return []comparator{{op: "<", version: next(parts)}}, nil
+		}
+		return []comparator{{op: "<=", version: v}}, nil
+	default:
+		return []comparator{{op: op, version: v}}, nil
+	}
+}
+
+// Allows tells if version satisfies the constraint.
+// Prerelease version is allowed only if comparators of the satisfied set name a prerelease
+// of the same major.minor.patch, so `^1.0.0` does not allow `1.2.0-beta`.
+func (c Constraint) Allows(v Version) bool {
+	if len(c.sets) == 0 {
+		return true
+	}
+	for _, set := range c.sets {
+		ok := true
+		namesPrerelease := v.Prerelease == ""
+		for _, item := range set {
+			if !item.allows(v) {
+				ok = false
+				break
+			}
+			if item.version.Prerelease != "" && item.version.samePatch(v) {
+				namesPrerelease = true
+			}
+		}
+		if ok && namesPrerelease {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Constraint) String() string {
+	if c.source == "" {
+		return "*"
+	}
+	return c.source
+}
+
+func (c Constraint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.String())
+}
+
+// UnmarshalJSON reads constraint string. Integer version `n` of older package files is read as `>=n`
+func (c *Constraint) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		*c = Constraint{
+			source: fmt.Sprintf(">=%d", n),
+			sets:   [][]comparator{{{op: ">=", version: Version{Major: n}}}},
+		}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseConstraint(s)
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
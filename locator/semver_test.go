@@ -0,0 +1,101 @@
+package locator
+
+import (
+	"testing"
+)
+
+func TestVersionCompare(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.10", "1.0.9", 1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", -1},
+		{"1.0.0-rc.1", "1.0.0-rc.1", 0},
+		{"1.0.0+build.1", "1.0.0", 0},
+	}
+	for _, tt := range tests {
+		a, err := ParseVersion(tt.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ParseVersion(tt.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := a.Compare(b); got != tt.expected {
+			t.Errorf("%s compared to %s: expected %d, got %d", tt.a, tt.b, tt.expected, got)
+		}
+		if got := b.Compare(a); got != -tt.expected {
+			t.Errorf("%s compared to %s: expected %d, got %d", tt.b, tt.a, -tt.expected, got)
+		}
+	}
+}
+
+func TestParseVersionErrors(t *testing.T) {
+	for _, s := range []string{"", "1", "1.2", "1.2.3.4", "1.a.3", "1.2.3-", "-1.2.3",
+		"1.0.0-a..b", "1.0.0-a.", "1.0.0-.a", "1.0.0-a b", "1.0.0-x/../../pwn", `1.0.0-a\b`, "1.0.0-é"} {
+		if _, err := ParseVersion(s); err == nil {
+			t.Errorf("expected error for `%s`", s)
+		}
+	}
+}
+
+func TestConstraintAllows(t *testing.T) {
+	tests := []struct {
+		constraint string
+		allowed    []string
+		rejected   []string
+	}{
+		{"", []string{"0.0.1", "1.0.0", "9.9.9"}, nil},
+		{"*", []string{"0.0.1", "9.9.9"}, []string{"1.0.0-beta"}},
+		{"1.2.3", []string{"1.2.3"}, []string{"1.2.4", "1.2.3-beta"}},
+		{"=1.2.3", []string{"1.2.3"}, []string{"1.2.2"}},
+		{"1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0", "1.1.9"}},
+		{"1.x", []string{"1.0.0", "1.9.9"}, []string{"2.0.0", "0.9.9"}},
+		{"^1.2.3", []string{"1.2.3", "1.9.0"}, []string{"1.2.2", "2.0.0", "1.3.0-beta", "2.0.0-rc.1"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0", "0.2.2"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"^1", []string{"1.0.0", "1.9.9"}, []string{"2.0.0"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.9"}, []string{"1.3.0", "1.2.2", "1.2.4-beta"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0"}},
+		{"^1.2.3-beta.2", []string{"1.2.3-beta.2", "1.2.3-beta.11", "1.2.3", "1.5.0"},
+			[]string{"1.2.3-beta.1", "1.2.4-beta.3", "2.0.0"}},
+		{">=1.0.0 <2.0.0", []string{"1.0.0", "1.9.9"}, []string{"2.0.0", "0.9.9", "1.5.0-beta"}},
+		{">1.2", []string{"1.3.0"}, []string{"1.2.9"}},
+		{"<=1.2", []string{"1.2.9"}, []string{"1.3.0"}},
+		{"1.x || ^3.1.0", []string{"1.5.0", "3.2.0"}, []string{"2.0.0", "3.0.0", "4.0.0"}},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("`%s`: %v", tt.constraint, err)
+		}
+		for _, s := range tt.allowed {
+			if v, _ := ParseVersion(s); !c.Allows(v) {
+				t.Errorf("`%s` should allow %s", tt.constraint, s)
+			}
+		}
+		for _, s := range tt.rejected {
+			if v, _ := ParseVersion(s); c.Allows(v) {
+				t.Errorf("`%s` should not allow %s", tt.constraint, s)
+			}
+		}
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, s := range []string{"^a.b", ">=1.2.3.4", "~1.2.3-", "1.2 || >=1.b"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("expected error for `%s`", s)
+		}
+	}
+}
@@ -38,6 +38,7 @@ const (
 	KwSelect   = "select"
 	KwCase     = "case"
 	KwEnd      = "end"
+	KwWhere    = "where"
 
 	SeqComment          = "//"
 	SeqCommentStart     = "/*"
@@ -60,15 +61,18 @@ const (
 	SeqLambdaBind       = "->"
 	SeqCaseBind         = "->"
 	SeqInfixChars       = "!#$%&*+-/:;<=>?^|~`"
+	SeqInterpolation    = "${"
+	SeqMultilineString  = "\"\"\""
 
 	SmbNewLine     = '\n'
 	SmbQuoteString = '"'
+	SmbRawString   = 'r'
 	SmbQuoteChar   = '\''
 	SmbEscape      = '\\'
 )
 
 var Keywords = []string{
-	KwModule, KwImport, KwAs, KwExposing, KwInfix, KwAlias, KwType, KwDef, KwHidden, KwNative, KwLeft, KwRight, KwNon, KwIf, KwThen, KwElse, KwLet, KwIn, KwSelect, KwCase, KwEnd,
+	KwModule, KwImport, KwAs, KwExposing, KwInfix, KwAlias, KwType, KwDef, KwHidden, KwNative, KwLeft, KwRight, KwNon, KwIf, KwThen, KwElse, KwLet, KwIn, KwSelect, KwCase, KwEnd, KwWhere,
 }
 
 // - void skip*() skips sequence if it can, returns nothing, does not set error.
@@ -124,6 +128,31 @@ func isIdentChar(c rune, first *bool, qualified bool) bool {
 	return false
 }
 
+// parseBinOpFunc reads infix operator or function name in infix form `|name|`
+func parseBinOpFunc(src *source) *parsed.BinOpItem {
+	cursor := src.cursor
+	if nil != readSequence(src, SeqBar) {
+		start := src.cursor
+		first := true
+		for isOk(src) && isIdentChar(src.text[src.cursor], &first, true) {
+			src.cursor++
+		}
+		end := src.cursor
+		if end > start && nil != readSequence(src, SeqBar) && (!isOk(src) || !isInfixChar(src.text[src.cursor])) {
+			location := ast.NewLocation(src.filePath, src.text, start, end)
+			skipComment(src)
+			return parsed.NewBinOpNamedFunc(location, ast.QualifiedIdentifier(src.text[start:end]))
+		}
+		src.cursor = cursor
+	}
+
+	infixOp := parseInfixIdentifier(src, false)
+	if nil != infixOp {
+		return parsed.NewBinOpFunc(*infixOp)
+	}
+	return nil
+}
+
 func isInfixChar(c rune) bool {
 	for _, x := range SeqInfixChars {
 		if c == x {
@@ -438,6 +467,8 @@ var controlCharsReplacer = strings.NewReplacer(
 	"\\t", "\t",
 	"\\v", "\v",
 	"\\\"", "\"",
+	"\\$", "$",
+	"\\\\", "\\",
 )
 
 func parseString(src *source) (*string, error) {
@@ -445,12 +476,45 @@ func parseString(src *source) (*string, error) {
 		return nil, nil
 	}
 
-	start := src.cursor
+	cursor := src.cursor
+
+	raw := false
+	if SmbRawString == src.text[src.cursor] {
+		src.cursor++
+		if !isOk(src) || SmbQuoteString != src.text[src.cursor] {
+			src.cursor = cursor
+			return nil, nil
+		}
+		raw = true
+	}
 
 	if SmbQuoteString != src.text[src.cursor] {
 		return nil, nil
 	}
 
+	if nil != readSequence(src, SeqMultilineString) {
+		start := src.cursor
+		skipNext := false
+		for {
+			if !isOk(src) {
+				return nil, newError(*src, "multiline string is not closed before the end of file")
+			}
+			end := src.cursor
+			if !skipNext && nil != readSequence(src, SeqMultilineString) {
+				str := trimIndent(string(src.text[start:end]))
+				skipComment(src)
+				if !raw {
+					str = controlCharsReplacer.Replace(str)
+				}
+				return &str, nil
+			}
+			skipNext = !raw && !skipNext && SmbEscape == src.text[src.cursor]
+			src.cursor++
+		}
+	}
+
+	start := src.cursor
+
 	src.cursor++
 	skipNextQuote := false
 	for {
@@ -460,16 +524,148 @@ func parseString(src *source) (*string, error) {
 		if SmbQuoteString == src.text[src.cursor] && !skipNextQuote {
 			break
 		}
-		skipNextQuote = SmbEscape == src.text[src.cursor]
+		skipNextQuote = !raw && !skipNextQuote && SmbEscape == src.text[src.cursor]
 		src.cursor++
 	}
 	src.cursor++
 	str := string(src.text[start+1 : src.cursor-1])
 	skipComment(src)
-	str = controlCharsReplacer.Replace(str)
+	if !raw {
+		str = controlCharsReplacer.Replace(str)
+	}
 	return &str, nil
 }
 
+// trimIndent removes leading and trailing blank lines of multiline string
+// and strips indentation common to all non-blank lines
+func trimIndent(str string) string {
+	lines := strings.Split(str, "\n")
+	if len(lines) > 1 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	if indent > 0 {
+		for i, line := range lines {
+			if len(line) >= indent {
+				lines[i] = line[indent:]
+			} else {
+				lines[i] = strings.TrimLeft(line, " \t")
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// holePlaceholder stands for interpolation holes while indentation of multiline string is trimmed
+const holePlaceholder = "\x00"
+
+func parseInterpolatedString(src *source) (parsed.Expression, error) {
+	if !isOk(src) {
+		return nil, nil
+	}
+
+	cursor := src.cursor
+
+	if SmbQuoteString != src.text[src.cursor] {
+		return nil, nil
+	}
+
+	multiline := nil != readSequence(src, SeqMultilineString)
+	if !multiline {
+		src.cursor++
+	}
+
+	//segments are text ranges around holes, there is always one segment more than holes
+	var segments [][2]uint32
+	var holes []parsed.Expression
+	segmentStart := src.cursor
+	skipNextQuote := false
+	for {
+		if !isOk(src) {
+			if multiline {
+				return nil, newError(*src, "multiline string is not closed before the end of file")
+			}
+			return nil, newError(*src, "string is not closed before the end of file")
+		}
+		end := src.cursor
+		if !skipNextQuote {
+			if multiline && nil != readSequence(src, SeqMultilineString) {
+				segments = append(segments, [2]uint32{segmentStart, end})
+				break
+			}
+			if !multiline && SmbQuoteString == src.text[src.cursor] {
+				segments = append(segments, [2]uint32{segmentStart, end})
+				src.cursor++
+				break
+			}
+			if nil != readSequence(src, SeqInterpolation) {
+				segments = append(segments, [2]uint32{segmentStart, end})
+				skipComment(src)
+				hole, err := parseExpression(src, false)
+				if err != nil {
+					return nil, err
+				}
+				if nil == hole {
+					return nil, newError(*src, "expected interpolated expression here")
+				}
+				holes = append(holes, hole)
+				if nil == readSequence(src, SeqBracesClose) {
+					return nil, newError(*src, "expected `}` here")
+				}
+				segmentStart = src.cursor
+				continue
+			}
+		}
+		skipNextQuote = !skipNextQuote && SmbEscape == src.text[src.cursor]
+		src.cursor++
+	}
+
+	if len(holes) == 0 {
+		src.cursor = cursor
+		return nil, nil
+	}
+
+	texts := make([]string, len(segments))
+	for i, s := range segments {
+		texts[i] = string(src.text[s[0]:s[1]])
+	}
+	if multiline {
+		texts = strings.Split(trimIndent(strings.Join(texts, holePlaceholder)), holePlaceholder)
+		if len(texts) != len(segments) {
+			return nil, newError(*src, "multiline string with interpolation cannot contain NUL character")
+		}
+	}
+
+	var parts []parsed.Expression
+	for i, text := range texts {
+		if text != "" {
+			parts = append(parts, parsed.NewConst(
+				ast.NewLocation(src.filePath, src.text, segments[i][0], segments[i][1]),
+				ast.CString{Value: controlCharsReplacer.Replace(text)}))
+		}
+		if i < len(holes) {
+			parts = append(parts, holes[i])
+		}
+	}
+
+	skipComment(src)
+	return parsed.NewInterpolation(loc(src, cursor), parts), nil
+}
+
 func parseNumber(src *source) (iValue *int64, fValue *float64, err error) {
 	pos := src.cursor
 	fv, err := parseFloat(src)
@@ -649,9 +845,15 @@ func parseType(src *source) (parsed.Type, error) {
 	//record
 	if readExact(src, SeqBracesOpen) {
 		recCursor := src.cursor
-		ext := readIdentifier(src, true)
-		if nil != ext && !readExact(src, SeqBar) {
-			ext = nil
+		var row parsed.Type
+		ext := readIdentifier(src, false)
+		extLoc := loc(src, recCursor)
+		if nil != ext && readExact(src, SeqBar) {
+			if !unicode.IsLower([]rune(*ext)[0]) {
+				return nil, newError(*src, "record extension should be a type parameter (starts with lowercase letter)")
+			}
+			row = parsed.NewTParameter(extLoc, ast.Identifier(*ext))
+		} else {
 			src.cursor = recCursor
 		}
 
@@ -687,7 +889,7 @@ func parseType(src *source) (parsed.Type, error) {
 			return nil, newError(*src, "expected `,` or `}` here")
 		}
 
-		return parsed.NewTRecord(loc(src, cursor), fields), nil
+		return parsed.NewTRecord(loc(src, cursor), fields, row), nil
 	}
 
 	nameStart := src.cursor
@@ -943,6 +1145,15 @@ func parseSignature(src *source) ([]parsed.Pattern, parsed.Type, error) {
 func parseExpression(src *source, negate bool) (parsed.Expression, error) {
 	cursor := src.cursor
 
+	//interpolated string
+	interpolation, err := parseInterpolatedString(src)
+	if err != nil {
+		return nil, err
+	}
+	if nil != interpolation {
+		return finishParseExpression(src, interpolation, negate)
+	}
+
 	//const
 	const_, err := parseConst(src)
 	if err != nil {
@@ -1044,58 +1255,14 @@ func parseExpression(src *source, negate bool) (parsed.Expression, error) {
 
 	//let
 	if readExact(src, KwLet) {
-		defCursor := src.cursor
-		name := readIdentifier(src, false)
-		nameLoc := loc(src, defCursor)
-		typeCursor := src.cursor
-		params, ret, err := parseSignature(src)
+		bind, isDef, err := parseLocalBinding(src)
 		if err != nil {
 			return nil, err
 		}
 
-		var pattern parsed.Pattern
-		var value parsed.Expression
-		var fnType parsed.Type
-		isDef := nil != name && nil != params && len(*name) > 0 && unicode.IsLower([]rune(*name)[0])
-		if isDef {
-			if !readExact(src, SeqEqual) {
-				return nil, newError(*src, "expected `=` here")
-			}
-			value, err = parseExpression(src, false)
-			if err != nil {
-				return nil, err
-			}
-			if nil == value {
-				return nil, newError(*src, "expected function body here")
-			}
-			pattern = parsed.NewPNamed(loc(src, defCursor), ast.Identifier(*name), nameLoc)
-			fnType = parsed.NewTFunc(
-				loc(src, typeCursor),
-				common.Map(func(x parsed.Pattern) parsed.Type { return x.Type() }, params),
-				ret)
-		} else {
-			src.cursor = defCursor
-			pattern, err = parsePattern(src)
-			if err != nil {
-				return nil, err
-			}
-			if nil == pattern {
-				return nil, newError(*src, "expected pattern here")
-			}
-			if !readExact(src, SeqEqual) {
-				return nil, newError(*src, "expected `=` here")
-			}
-			value, err = parseExpression(src, false)
-			if err != nil {
-				return nil, err
-			}
-			if nil == value {
-				return nil, newError(*src, "expected expression here")
-			}
-		}
-
 		preLet := src.cursor
-		if readExact(src, KwLet) {
+		consecutive := readExact(src, KwLet)
+		if consecutive {
 			src.cursor = preLet
 		} else if !readExact(src, KwIn) {
 			return nil, newError(*src, "expected `let` or `in` here")
@@ -1105,15 +1272,11 @@ func parseExpression(src *source, negate bool) (parsed.Expression, error) {
 		if nil == nested {
 			return nil, newError(*src, "expected expression here")
 		}
-		if isDef {
-			return finishParseExpression(src,
-				parsed.NewFunction(loc(src, cursor), ast.Identifier(*name), nameLoc, params, value, fnType, nested),
-				negate)
-		} else {
-			return finishParseExpression(src,
-				parsed.NewLet(loc(src, cursor), pattern, value, nested),
-				negate)
+		expr := bind(loc(src, cursor), nested)
+		if consecutive && isDef {
+			expr = parsed.JoinFunctionGroup(loc(src, cursor), expr)
 		}
+		return finishParseExpression(src, expr, negate)
 	}
 
 	//select
@@ -1228,6 +1391,25 @@ func parseExpression(src *source, negate bool) (parsed.Expression, error) {
 			return finishParseExpression(src, parsed.NewConst(loc(src, cursor), ast.CUnit{}), negate)
 		}
 
+		//right section
+		sectionCursor := src.cursor
+		if fn := parseBinOpFunc(src); nil != fn {
+			if fn.Infix() != SeqMinus {
+				operand, err := parseExpression(src, false)
+				if err != nil {
+					return nil, err
+				}
+				if nil == operand {
+					return nil, newError(*src, "expected operator section operand expression here")
+				}
+				if !readExact(src, SeqParenthesisClose) {
+					return nil, newError(*src, "expected `)` here")
+				}
+				return finishParseExpression(src, parsed.NewSection(loc(src, cursor), fn, operand, false), negate)
+			}
+			src.cursor = sectionCursor
+		}
+
 		var items []parsed.Expression
 		for {
 			expr, err := parseExpression(src, false)
@@ -1248,12 +1430,19 @@ func parseExpression(src *source, negate bool) (parsed.Expression, error) {
 			return nil, newError(*src, "expected `,` or `)` here")
 		}
 
+		if len(items) > 1 && slices.ContainsFunc(items, isOpenSection) {
+			return nil, newError(*src, "operator section should be enclosed in its own parentheses")
+		}
 		if 1 == len(items) {
 			expr := items[0]
 			if bop, ok := expr.(*parsed.BinOp); ok {
 				bop.SetInParentheses(true)
 				expr = bop
 			}
+			if section, ok := expr.(*parsed.Section); ok {
+				section.SetInParentheses(true)
+				expr = section
+			}
 			return finishParseExpression(src, expr, negate)
 		} else {
 			return finishParseExpression(src, parsed.NewTuple(loc(src, cursor), items), negate)
@@ -1268,11 +1457,28 @@ func parseExpression(src *source, negate bool) (parsed.Expression, error) {
 	return nil, nil
 }
 
+// isOpenSection tells if expression is a left operator section that is not closed by its own parentheses
+func isOpenSection(expr parsed.Expression) bool {
+	section, ok := expr.(*parsed.Section)
+	return ok && !section.InParentheses()
+}
+
 func finishParseExpression(src *source, expr parsed.Expression, negate bool) (parsed.Expression, error) {
 	cursor := src.cursor
 
-	infixOp := parseInfixIdentifier(src, false)
-	if nil != infixOp {
+	fn := parseBinOpFunc(src)
+	if nil != fn {
+		if negate {
+			expr = parsed.NewNegate(loc(src, cursor), expr)
+		}
+
+		//left section
+		sectionCursor := src.cursor
+		if readExact(src, SeqParenthesisClose) {
+			src.cursor = sectionCursor
+			return parsed.NewSection(loc(src, expr.Location().Start()), fn, expr, true), nil
+		}
+
 		final, err := parseExpression(src, false)
 		if err != nil {
 			return nil, err
@@ -1281,13 +1487,15 @@ func finishParseExpression(src *source, expr parsed.Expression, negate bool) (pa
 			return nil, newError(*src, "expected second operand expression of binary expression here")
 		}
 
-		if negate {
-			expr = parsed.NewNegate(loc(src, cursor), expr)
-		}
-
 		items := []*parsed.BinOpItem{
 			parsed.NewBinOpOperand(expr),
-			parsed.NewBinOpFunc(*infixOp),
+			fn,
+		}
+
+		section, isSection := final.(*parsed.Section)
+		isSection = isSection && !section.InParentheses()
+		if isSection {
+			final = section.Operand()
 		}
 
 		if bop, ok := final.(*parsed.BinOp); ok && !bop.InParentheses() {
@@ -1296,7 +1504,11 @@ func finishParseExpression(src *source, expr parsed.Expression, negate bool) (pa
 			items = append(items, parsed.NewBinOpOperand(final))
 		}
 
-		return parsed.NewBinOp(loc(src, expr.Location().Start()), items, false), nil
+		bop := parsed.NewBinOp(loc(src, expr.Location().Start()), items, false)
+		if isSection {
+			return parsed.NewSection(bop.Location(), section.Func(), bop, true), nil
+		}
+		return bop, nil
 	}
 
 	if readExact(src, SeqParenthesisOpen) {
@@ -1309,6 +1521,9 @@ func finishParseExpression(src *source, expr parsed.Expression, negate bool) (pa
 			if nil == item {
 				return nil, newError(*src, "expected function argument expression here")
 			}
+			if isOpenSection(item) {
+				return nil, newError(*src, "operator section should be enclosed in its own parentheses")
+			}
 			items = append(items, item)
 
 			if readExact(src, SeqComma) {
@@ -1610,6 +1825,95 @@ func parseDataType(src *source) (parsed.DataType, error) {
 	return parsed.NewDataType(loc(src, cursor), hidden, name, params, options, nameLoc), err
 }
 
+// parseLocalBinding parses local function or pattern binding of `let` or `where` clause.
+// Returns function that wraps nested expression with parsed binding and whether the binding is a function.
+func parseLocalBinding(src *source) (func(ast.Location, parsed.Expression) parsed.Expression, bool, error) {
+	defCursor := src.cursor
+	name := readIdentifier(src, false)
+	nameLoc := loc(src, defCursor)
+	typeCursor := src.cursor
+	params, ret, err := parseSignature(src)
+	if err != nil {
+		return nil, false, err
+	}
+
+	isDef := nil != name && nil != params && len(*name) > 0 && unicode.IsLower([]rune(*name)[0])
+	if isDef {
+		if !readExact(src, SeqEqual) {
+			return nil, false, newError(*src, "expected `=` here")
+		}
+		value, err := parseExpression(src, false)
+		if err != nil {
+			return nil, false, err
+		}
+		if nil == value {
+			return nil, false, newError(*src, "expected function body here")
+		}
+		fnType := parsed.NewTFunc(
+			loc(src, typeCursor),
+			common.Map(func(x parsed.Pattern) parsed.Type { return x.Type() }, params),
+			ret)
+		return func(location ast.Location, nested parsed.Expression) parsed.Expression {
+			return parsed.NewFunction(location, ast.Identifier(*name), nameLoc, params, value, fnType, nested)
+		}, true, nil
+	}
+
+	src.cursor = defCursor
+	pattern, err := parsePattern(src)
+	if err != nil {
+		return nil, false, err
+	}
+	if nil == pattern {
+		return nil, false, newError(*src, "expected pattern here")
+	}
+	if !readExact(src, SeqEqual) {
+		return nil, false, newError(*src, "expected `=` here")
+	}
+	value, err := parseExpression(src, false)
+	if err != nil {
+		return nil, false, err
+	}
+	if nil == value {
+		return nil, false, newError(*src, "expected expression here")
+	}
+	return func(location ast.Location, nested parsed.Expression) parsed.Expression {
+		return parsed.NewLet(location, pattern, value, nested)
+	}, false, nil
+}
+
+// parseWhere parses optional `where` clause of definition and wraps body with its bindings.
+// Bindings are scoped like consecutive `let` bindings: each one is visible in the body and in the bindings
+// that follow it, consecutive function bindings are mutually visible.
+func parseWhere(src *source, body parsed.Expression) (parsed.Expression, error) {
+	if !readExact(src, KwWhere) {
+		return body, nil
+	}
+
+	var binds []func(ast.Location, parsed.Expression) parsed.Expression
+	var isDefs []bool
+	for {
+		bind, isDef, err := parseLocalBinding(src)
+		if err != nil {
+			return nil, err
+		}
+		binds = append(binds, bind)
+		isDefs = append(isDefs, isDef)
+
+		if !readExact(src, SeqComma) {
+			break
+		}
+	}
+
+	location := loc(src, body.Location().Start())
+	for i := len(binds) - 1; i >= 0; i-- {
+		body = binds[i](location, body)
+		if i < len(binds)-1 && isDefs[i] {
+			body = parsed.JoinFunctionGroup(location, body)
+		}
+	}
+	return body, nil
+}
+
 func parseDefinition(src *source, modName ast.QualifiedIdentifier) (parsed.Definition, error) {
 	cursor := src.cursor
 
@@ -1698,6 +2002,9 @@ func parseDefinition(src *source, modName ast.QualifiedIdentifier) (parsed.Defin
 			}
 		}
 	}
+	if err == nil && !native {
+		body, err = parseWhere(src, body)
+	}
 	return parsed.NewDefinition(loc(src, cursor), hidden, ast.Identifier(*name), nameLocation, params, body, type_), err
 }
 
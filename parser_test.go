@@ -0,0 +1,82 @@
+package nar_compiler
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nar-lang/nar-compiler/ast"
+	"github.com/nar-lang/nar-compiler/ast/parsed"
+)
+
+// parseTestDefinition parses module `Test` and returns body of its definition
+func parseTestDefinition(t *testing.T, src string, name ast.Identifier) parsed.Expression {
+	t.Helper()
+	m, errs := Parse("test.nar", []rune(src))
+	for _, err := range errs {
+		t.Fatal(err)
+	}
+	for _, def := range m.Definitions() {
+		if def.Name() == name {
+			return def.Body()
+		}
+	}
+	t.Fatalf("definition `%s` is not found", name)
+	return nil
+}
+
+// expectParseError parses module and checks that one of reported errors contains the message
+func expectParseError(t *testing.T, src string, message string) {
+	t.Helper()
+	_, errs := Parse("test.nar", []rune(src))
+	for _, err := range errs {
+		if strings.Contains(err.Error(), message) {
+			return
+		}
+	}
+	t.Errorf("expected error `%s`, got %v", message, errs)
+}
+
+func TestParseWhere(t *testing.T) {
+	src := `
+module Test
+
+def ordered(y: Int): Int = helper(y)
+  where k = 100,
+    helper(z: Int): Int = z + k
+
+def grouped(n: Int): Bool = isEven(n)
+  where isEven(i: Int): Bool = isOdd(i),
+    isOdd(i: Int): Bool = isEven(i)
+`
+	if _, ok := parseTestDefinition(t, src, "ordered").(*parsed.Let); !ok {
+		t.Errorf("first `where` binding should be the outermost")
+	}
+	if _, ok := parseTestDefinition(t, src, "grouped").(*parsed.FunctionGroup); !ok {
+		t.Errorf("consecutive `where` functions should form a group")
+	}
+
+	expectParseError(t, `
+module Test
+
+def f(y: Int): Int = y where
+`, "expected pattern here")
+}
+
+func TestParseSections(t *testing.T) {
+	src := `
+module Test
+
+def left = (1 +)
+def right = (+ 1)
+def named = (|max| 1)
+`
+	for _, name := range []ast.Identifier{"left", "right", "named"} {
+		if _, ok := parseTestDefinition(t, src, name).(*parsed.Section); !ok {
+			t.Errorf("`%s` should be parsed as operator section", name)
+		}
+	}
+
+	for _, body := range []string{"f(1 +)", "f(2, 1 +)", "(2, 1 +)"} {
+		expectParseError(t, "module Test\n\ndef x = "+body+"\n", "operator section should be enclosed in its own parentheses")
+	}
+}